@@ -1,4 +1,30 @@
 package main
 
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/abdivasiyev/rester/internal/use_case/health"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+	"github.com/abdivasiyev/rester/pkg/slogx"
+)
+
 func main() {
+	var (
+		logger        = slogx.New()
+		healthUseCase = health.New(logger, health.NewRegistry(time.Second))
+		mux           = http.NewServeMux()
+	)
+
+	mux.HandleFunc("GET /health", httpx.HandleNoReq(healthUseCase.Health, httpx.WithLogger(logger)))
+	mux.HandleFunc("GET /health/live", httpx.HandleNoReq(healthUseCase.Liveness, httpx.WithLogger(logger)))
+	mux.HandleFunc("GET /health/ready", httpx.HandleNoReq(healthUseCase.Readiness, httpx.WithLogger(logger)))
+
+	if err := httpx.ListenAndServe(context.Background(), ":8080", mux, httpx.WithServerLogger(logger)); err != nil {
+		logger.Error("server failed", slog.Any("err", err))
+		os.Exit(1)
+	}
 }
@@ -3,25 +3,71 @@ package health
 import (
 	"context"
 	"log/slog"
+	"net/http"
 
 	"github.com/abdivasiyev/rester/pkg/httpx"
 )
 
+type ProbeStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Response struct {
+	Healthy bool          `json:"healthy"`
+	Probes  []ProbeStatus `json:"probes"`
+}
+
 type UseCase interface {
-	Health(ctx context.Context, request httpx.DefaultRequest) (httpx.DefaultResponse, error)
+	Health(ctx context.Context) (httpx.DefaultResponse, error)
+	Liveness(ctx context.Context) (httpx.Result[Response], error)
+	Readiness(ctx context.Context) (httpx.Result[Response], error)
 }
 
 type useCase struct {
-	logger *slog.Logger
+	logger   *slog.Logger
+	registry *Registry
 }
 
-func New(logger *slog.Logger) UseCase {
+func New(logger *slog.Logger, registry *Registry) UseCase {
+	if registry == nil {
+		registry = NewRegistry(defaultTimeout)
+	}
+
 	return &useCase{
-		logger: logger,
+		logger:   logger,
+		registry: registry,
 	}
 }
 
-func (u *useCase) Health(ctx context.Context, request httpx.DefaultRequest) (httpx.DefaultResponse, error) {
+func (u *useCase) Health(ctx context.Context) (httpx.DefaultResponse, error) {
 	u.logger.Info("Health check")
 	return httpx.DefaultResponse{Message: "OK"}, nil
 }
+
+func (u *useCase) Liveness(ctx context.Context) (httpx.Result[Response], error) {
+	return u.result(u.registry.runLiveness(ctx)), nil
+}
+
+func (u *useCase) Readiness(ctx context.Context) (httpx.Result[Response], error) {
+	return u.result(u.registry.runReadiness(ctx)), nil
+}
+
+// result reports probes with [http.StatusServiceUnavailable] when any of them failed, so the body lists failing probes
+func (u *useCase) result(probes []ProbeStatus) httpx.Result[Response] {
+	response := Response{Healthy: true, Probes: probes}
+
+	for _, probe := range probes {
+		if !probe.Healthy {
+			response.Healthy = false
+			u.logger.Warn("Health probe failed", slog.String("probe", probe.Name), slog.String("err", probe.Error))
+		}
+	}
+
+	if !response.Healthy {
+		return httpx.Result[Response]{Code: http.StatusServiceUnavailable, Value: response}
+	}
+
+	return httpx.Result[Response]{Value: response}
+}
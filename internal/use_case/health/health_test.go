@@ -0,0 +1,58 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/internal/use_case/health"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestReadiness(t *testing.T) {
+	var (
+		logger   = slog.New(slog.NewJSONHandler(io.Discard, nil))
+		registry = health.NewRegistry(time.Second)
+	)
+	registry.Register("db", func(context.Context) error { return nil })
+	registry.Register("cache", func(context.Context) error { return errors.New("connection refused") })
+
+	handler := httpx.HandleNoReq(health.New(logger, registry).Readiness, httpx.WithLogger(logger))
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	var response health.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Healthy || len(response.Probes) != 2 {
+		t.Fatalf("response = %+v, want unhealthy with both probes", response)
+	}
+	for _, probe := range response.Probes {
+		if want := probe.Name == "db"; probe.Healthy != want {
+			t.Errorf("probe %s healthy = %v, want %v", probe.Name, probe.Healthy, want)
+		}
+	}
+}
+
+func TestLivenessHealthy(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	handler := httpx.HandleNoReq(health.New(logger, nil).Liveness, httpx.WithLogger(logger))
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
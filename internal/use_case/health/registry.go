@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+var errTimeout = errors.New("probe timed out")
+
+type Check func(ctx context.Context) error
+
+type probe struct {
+	name  string
+	check Check
+}
+
+type Registry struct {
+	mu        sync.RWMutex
+	timeout   time.Duration
+	liveness  []probe
+	readiness []probe
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Registry{
+		timeout: timeout,
+	}
+}
+
+// Register adds readiness probe, e.g. database or cache ping
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.readiness = append(r.readiness, probe{name: name, check: check})
+}
+
+// RegisterLiveness adds liveness probe, which should fail only when the process must be restarted
+func (r *Registry) RegisterLiveness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.liveness = append(r.liveness, probe{name: name, check: check})
+}
+
+func (r *Registry) runLiveness(ctx context.Context) []ProbeStatus {
+	r.mu.RLock()
+	probes := append([]probe(nil), r.liveness...)
+	r.mu.RUnlock()
+
+	return r.run(ctx, probes)
+}
+
+func (r *Registry) runReadiness(ctx context.Context) []ProbeStatus {
+	r.mu.RLock()
+	probes := append([]probe(nil), r.readiness...)
+	r.mu.RUnlock()
+
+	return r.run(ctx, probes)
+}
+
+// run executes probes concurrently, probes not finished within timeout are reported as failed
+func (r *Registry) run(ctx context.Context, probes []probe) []ProbeStatus {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var (
+		statuses = make([]ProbeStatus, len(probes))
+		results  = make([]chan error, len(probes))
+	)
+
+	for i, p := range probes {
+		results[i] = make(chan error, 1)
+		go func() {
+			results[i] <- p.check(ctx)
+		}()
+	}
+
+	for i, p := range probes {
+		var err error
+		select {
+		case err = <-results[i]:
+		case <-ctx.Done():
+			err = errTimeout
+		}
+
+		statuses[i] = ProbeStatus{Name: p.name, Healthy: err == nil}
+		if err != nil {
+			statuses[i].Error = err.Error()
+		}
+	}
+
+	return statuses
+}
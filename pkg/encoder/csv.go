@@ -0,0 +1,112 @@
+package encoder
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var CsvEncoder Encoder = &csvEncoder{}
+
+type csvEncoder struct {
+	w io.Writer
+}
+
+func (e *csvEncoder) New(w io.Writer) Encoder {
+	return &csvEncoder{
+		w: w,
+	}
+}
+
+func (e *csvEncoder) Encode(src any) error {
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("csv: cannot encode %T, slice of structs is expected", src)
+	}
+
+	elemType := v.Type().Elem()
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: cannot encode %T, slice of structs is expected", src)
+	}
+
+	var (
+		fields []int
+		header []string
+	)
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("csv"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, i)
+		header = append(header, name)
+	}
+
+	writer := csv.NewWriter(e.w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		for elem.Kind() == reflect.Pointer && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+
+		for j, field := range fields {
+			if elem.Kind() != reflect.Struct {
+				record[j] = ""
+				continue
+			}
+
+			value, err := formatCsvValue(elem.Field(field))
+			if err != nil {
+				return err
+			}
+			record[j] = value
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (e *csvEncoder) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func formatCsvValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	return fmt.Sprint(v.Interface()), nil
+}
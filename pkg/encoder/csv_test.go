@@ -0,0 +1,71 @@
+package encoder_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+func TestCsvEncoder(t *testing.T) {
+	type row struct {
+		ID       int       `csv:"id"`
+		Name     string    `csv:"name"`
+		Created  time.Time `csv:"created"`
+		Note     *string
+		Password string `csv:"-"`
+		internal string
+	}
+
+	note := "vip"
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := map[string]struct {
+		src  any
+		want string
+	}{
+		"slice of structs": {
+			src: []row{
+				{ID: 1, Name: "bob", Created: created, Note: &note, Password: "secret"},
+				{ID: 2, Name: "alice, jr", Created: created},
+			},
+			want: "id,name,created,Note\n" +
+				"1,bob,2024-01-02T03:04:05Z,vip\n" +
+				"2,\"alice, jr\",2024-01-02T03:04:05Z,\n",
+		},
+		"slice of pointers": {
+			src:  []*row{{ID: 1, Name: "bob", Created: created}, nil},
+			want: "id,name,created,Note\n1,bob,2024-01-02T03:04:05Z,\n,,,\n",
+		},
+		"empty slice": {
+			src:  []row{},
+			want: "id,name,created,Note\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encoder.CsvEncoder.New(&buf).Encode(tt.src); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("body = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCsvEncoderRejectsNonSlices(t *testing.T) {
+	for name, src := range map[string]any{
+		"struct":           user{ID: 1},
+		"slice of strings": []string{"a"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := encoder.CsvEncoder.New(&bytes.Buffer{}).Encode(src); err == nil {
+				t.Error("Encode() error = nil, want error")
+			}
+		})
+	}
+}
@@ -12,3 +12,40 @@ type Encoder interface {
 	New(w io.Writer) Encoder
 	Encode(src any) error
 }
+
+// A WriterEncoder encodes values without keeping state between them, so it encodes src into w without
+// allocating a new encoder with New. Handlers of httpx encode responses with it when encoder implements it
+type WriterEncoder interface {
+	EncodeTo(w io.Writer, src any) error
+}
+
+type Decoder interface {
+	New(r io.Reader) Decoder
+	Decode(dst any) error
+}
+
+// WithContentType decorates e to report contentType, e.g. vendor media type application/vnd.myapp.v2+json
+// for JSON encoder. Content type with parameters is sent as is
+func WithContentType(e Encoder, contentType string) Encoder {
+	return &contentTypeEncoder{Encoder: e, contentType: contentType}
+}
+
+type contentTypeEncoder struct {
+	Encoder
+	contentType string
+}
+
+func (e *contentTypeEncoder) New(w io.Writer) Encoder {
+	return &contentTypeEncoder{Encoder: e.Encoder.New(w), contentType: e.contentType}
+}
+
+func (e *contentTypeEncoder) EncodeTo(w io.Writer, src any) error {
+	if writerEncoder, ok := e.Encoder.(WriterEncoder); ok {
+		return writerEncoder.EncodeTo(w, src)
+	}
+	return e.Encoder.New(w).Encode(src)
+}
+
+func (e *contentTypeEncoder) ContentType() string {
+	return e.contentType
+}
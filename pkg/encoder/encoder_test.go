@@ -0,0 +1,28 @@
+package encoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+func TestWithContentType(t *testing.T) {
+	const vendor = "application/vnd.myapp.v2+json"
+	enc := encoder.WithContentType(encoder.JsonEncoder, vendor)
+
+	var buf bytes.Buffer
+	writer := enc.New(&buf)
+	if err := writer.Encode(map[string]int{"a": 1}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	if got := buf.String(); got != `{"a":1}`+"\n" {
+		t.Errorf("body = %q, want JSON", got)
+	}
+	for name, e := range map[string]encoder.Encoder{"encoder": enc, "writer": writer} {
+		if got := contentType(e); got != vendor {
+			t.Errorf("%s ContentType() = %q, want %q", name, got, vendor)
+		}
+	}
+}
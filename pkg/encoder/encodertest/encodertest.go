@@ -0,0 +1,107 @@
+// Package encodertest provides you with mock encoders and decoders to test error paths of handlers
+package encodertest
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+// MockEncoder records encoded values and returns Err from Encode. Written body is empty, Content-Type header
+// is set to Type when it is not empty. MockEncoder is safe for concurrent use
+//
+// Usage:
+//
+//	enc := &encodertest.MockEncoder{Err: errors.New("boom")}
+//	h := httpx.Handle[Request, Response](useCase, httpx.WithEncoder(enc))
+type MockEncoder struct {
+	Err  error
+	Type string
+
+	mu      sync.Mutex
+	encoded []any
+}
+
+var (
+	_ encoder.Encoder      = (*MockEncoder)(nil)
+	_ encoder.ContentTyper = (*MockEncoder)(nil)
+)
+
+// New returns e itself, so values encoded by all writers are recorded together
+func (e *MockEncoder) New(io.Writer) encoder.Encoder {
+	return e
+}
+
+func (e *MockEncoder) Encode(src any) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.encoded = append(e.encoded, src)
+	return e.Err
+}
+
+func (e *MockEncoder) ContentType() string {
+	return e.Type
+}
+
+// Encoded returns values passed to Encode in order
+func (e *MockEncoder) Encoded() []any {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]any(nil), e.encoded...)
+}
+
+// MockDecoder returns Err from Decode, otherwise Value is assigned to the value dst points to.
+// Value must be assignable to it, e.g. Request for *Request destination
+type MockDecoder struct {
+	Err   error
+	Value any
+
+	mu    sync.Mutex
+	calls int
+}
+
+var _ encoder.Decoder = (*MockDecoder)(nil)
+
+// New returns d itself, so calls of all readers are counted together
+func (d *MockDecoder) New(io.Reader) encoder.Decoder {
+	return d
+}
+
+func (d *MockDecoder) Decode(dst any) error {
+	d.mu.Lock()
+	d.calls++
+	d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if d.Value == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("encodertest: cannot decode into %T", dst)
+	}
+
+	value := reflect.ValueOf(d.Value)
+	if !value.Type().AssignableTo(v.Elem().Type()) {
+		return fmt.Errorf("encodertest: cannot assign %T to %T", d.Value, dst)
+	}
+	v.Elem().Set(value)
+
+	return nil
+}
+
+// Calls returns number of Decode calls
+func (d *MockDecoder) Calls() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.calls
+}
@@ -0,0 +1,115 @@
+package encodertest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder/encodertest"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type request struct {
+	Name string
+}
+
+func (r *request) Bind(req *http.Request) error {
+	return httpx.BindBody(req, decoder, r)
+}
+
+func (r *request) Validate() error {
+	return nil
+}
+
+func (r request) String() string {
+	return r.Name
+}
+
+// decoder binds every request as bob
+var decoder = &encodertest.MockDecoder{Value: request{Name: "bob"}}
+
+// discard drops logs of handlers
+var discard = httpx.WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+
+func ExampleMockEncoder() {
+	enc := &encodertest.MockEncoder{Err: errors.New("boom"), Type: "application/json"}
+	handler := httpx.Handle(func(context.Context, request) (string, error) {
+		return "hello", nil
+	}, httpx.WithEncoder(enc), discard)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")))
+
+	fmt.Println(w.Code)
+	fmt.Println(enc.Encoded()[0])
+	// Output:
+	// 500
+	// hello
+}
+
+func ExampleMockDecoder() {
+	handler := httpx.Handle(func(_ context.Context, req request) (string, error) {
+		return "hello " + req.Name, nil
+	}, discard)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")))
+
+	fmt.Print(w.Body.String())
+	// Output:
+	// "hello bob"
+}
+
+func TestMockEncoder(t *testing.T) {
+	enc := &encodertest.MockEncoder{Type: "text/plain"}
+
+	for _, v := range []any{1, "a"} {
+		if err := enc.New(io.Discard).Encode(v); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+
+	if got := enc.Encoded(); len(got) != 2 || got[0] != 1 || got[1] != "a" {
+		t.Errorf("Encoded() = %v, want [1 a]", got)
+	}
+	if got := enc.ContentType(); got != "text/plain" {
+		t.Errorf("ContentType() = %q, want %q", got, "text/plain")
+	}
+}
+
+func TestMockDecoder(t *testing.T) {
+	boom := errors.New("boom")
+
+	tests := map[string]struct {
+		decoder *encodertest.MockDecoder
+		dst     any
+		wantErr bool
+	}{
+		"value":          {decoder: &encodertest.MockDecoder{Value: request{Name: "bob"}}, dst: new(request)},
+		"no value":       {decoder: &encodertest.MockDecoder{}, dst: new(request)},
+		"preset error":   {decoder: &encodertest.MockDecoder{Err: boom}, dst: new(request), wantErr: true},
+		"not assignable": {decoder: &encodertest.MockDecoder{Value: 1}, dst: new(request), wantErr: true},
+		"not a pointer":  {decoder: &encodertest.MockDecoder{Value: request{}}, dst: request{}, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.decoder.New(strings.NewReader("")).Decode(tt.dst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.decoder.Err != nil && !errors.Is(err, tt.decoder.Err) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.decoder.Err)
+			}
+			if tt.decoder.Calls() != 1 {
+				t.Errorf("Calls() = %d, want 1", tt.decoder.Calls())
+			}
+		})
+	}
+}
@@ -3,20 +3,210 @@ package encoder
 import (
 	"encoding/json"
 	"io"
+	"reflect"
+	"sync"
 )
 
-var JsonEncoder Encoder = &jsonEncoder{}
+var JsonEncoder = NewJSONEncoder()
 
 type jsonEncoder struct {
-	encoder *json.Encoder
+	encoder    *json.Encoder
+	indent     string
+	escapeHTML bool
+	encoders   *sync.Pool
+}
+
+type JSONOption func(e *jsonEncoder)
+
+func WithIndent(indent string) JSONOption {
+	return func(e *jsonEncoder) {
+		e.indent = indent
+	}
+}
+
+func WithEscapeHTML(escapeHTML bool) JSONOption {
+	return func(e *jsonEncoder) {
+		e.escapeHTML = escapeHTML
+	}
+}
+
+func NewJSONEncoder(options ...JSONOption) Encoder {
+	var e = jsonEncoder{escapeHTML: true, encoders: new(sync.Pool)}
+	for _, opt := range options {
+		opt(&e)
+	}
+
+	return &e
 }
 
 func (d *jsonEncoder) New(w io.Writer) Encoder {
 	return &jsonEncoder{
-		encoder: json.NewEncoder(w),
+		encoder:    d.newEncoder(w),
+		indent:     d.indent,
+		escapeHTML: d.escapeHTML,
+		encoders:   d.encoders,
 	}
 }
 
+func (d *jsonEncoder) newEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	if d.indent != "" {
+		encoder.SetIndent("", d.indent)
+	}
+	encoder.SetEscapeHTML(d.escapeHTML)
+
+	return encoder
+}
+
 func (d *jsonEncoder) Encode(src any) error {
 	return d.encoder.Encode(src)
 }
+
+// EncodeTo encodes src into w with encoder taken from the pool, non-pointer values are encoded through pooled
+// pointers to their copies, so encoding/json does not allocate a copy to address them
+func (d *jsonEncoder) EncodeTo(w io.Writer, src any) error {
+	e, _ := d.encoders.Get().(*pooledJSONEncoder)
+	if e == nil {
+		e = new(pooledJSONEncoder)
+		e.encoder = d.newEncoder(e)
+	}
+
+	e.w = w
+	err := encodeAddressable(e.encoder, src)
+	e.w = nil
+
+	// json.Encoder keeps write errors, so encoder which failed is dropped
+	if err == nil {
+		d.encoders.Put(e)
+	}
+	return err
+}
+
+// pooledJSONEncoder is a json.Encoder writing to w, w is replaced by every EncodeTo
+type pooledJSONEncoder struct {
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+func (e *pooledJSONEncoder) Write(p []byte) (int, error) {
+	return e.w.Write(p)
+}
+
+// pointerPools holds *sync.Pool of pointers per type of encoded non-pointer values, pool is nil
+// for types which are encoded differently through pointers
+var pointerPools sync.Map
+
+// encodeAddressable encodes copy of src stored in pooled pointer, src is encoded as is when there is no pool for its type
+func encodeAddressable(encoder *json.Encoder, src any) error {
+	pool := pointerPool(reflect.TypeOf(src))
+	if pool == nil {
+		return encoder.Encode(src)
+	}
+
+	ptr := pool.Get()
+	value := reflect.ValueOf(ptr).Elem()
+	value.Set(reflect.ValueOf(src))
+	err := encoder.Encode(ptr)
+	value.SetZero()
+	pool.Put(ptr)
+
+	return err
+}
+
+func pointerPool(t reflect.Type) *sync.Pool {
+	if t == nil || t.Kind() == reflect.Pointer {
+		return nil
+	}
+	if pool, ok := pointerPools.Load(t); ok {
+		return pool.(*sync.Pool)
+	}
+
+	var pool *sync.Pool
+	if !hasPointerMethods(t) {
+		pool = &sync.Pool{New: func() any {
+			return reflect.New(t).Interface()
+		}}
+	}
+	actual, _ := pointerPools.LoadOrStore(t, pool)
+
+	return actual.(*sync.Pool)
+}
+
+// hasPointerMethods reports whether t or values stored in it in place have methods with pointer receivers,
+// encoding/json calls them only for addressable values, so such values are not encoded through pointers
+func hasPointerMethods(t reflect.Type) bool {
+	if reflect.PointerTo(t).NumMethod() != t.NumMethod() {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointerMethods(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return hasPointerMethods(t.Elem())
+	}
+
+	return false
+}
+
+func (d *jsonEncoder) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+var JsonDecoder = NewJSONDecoder()
+
+type jsonDecoder struct {
+	decoder               *json.Decoder
+	useNumber             bool
+	disallowUnknownFields bool
+}
+
+type JSONDecoderOption func(d *jsonDecoder)
+
+// WithUseNumber decodes numbers into interface values as json.Number instead of float64,
+// so large integers keep precision
+func WithUseNumber() JSONDecoderOption {
+	return func(d *jsonDecoder) {
+		d.useNumber = true
+	}
+}
+
+// WithDisallowUnknownFields rejects objects with keys which do not match any exported field of destination
+func WithDisallowUnknownFields() JSONDecoderOption {
+	return func(d *jsonDecoder) {
+		d.disallowUnknownFields = true
+	}
+}
+
+func NewJSONDecoder(options ...JSONDecoderOption) Decoder {
+	var d jsonDecoder
+	for _, opt := range options {
+		opt(&d)
+	}
+
+	return &d
+}
+
+func (d *jsonDecoder) New(r io.Reader) Decoder {
+	decoder := json.NewDecoder(r)
+	if d.useNumber {
+		decoder.UseNumber()
+	}
+	if d.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	return &jsonDecoder{
+		decoder:               decoder,
+		useNumber:             d.useNumber,
+		disallowUnknownFields: d.disallowUnknownFields,
+	}
+}
+
+func (d *jsonDecoder) Decode(dst any) error {
+	return d.decoder.Decode(dst)
+}
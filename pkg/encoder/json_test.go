@@ -0,0 +1,132 @@
+package encoder_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+func TestJSONEncoderEscapeHTML(t *testing.T) {
+	src := map[string]string{"link": "<a href=\"/?a=1&b=2\">"}
+
+	tests := map[string]struct {
+		encoder encoder.Encoder
+		want    string
+	}{
+		"default escapes": {
+			encoder: encoder.JsonEncoder,
+			want:    `{"link":"\u003ca href=\"/?a=1\u0026b=2\"\u003e"}` + "\n",
+		},
+		"escaping disabled": {
+			encoder: encoder.NewJSONEncoder(encoder.WithEscapeHTML(false)),
+			want:    `{"link":"<a href=\"/?a=1&b=2\">"}` + "\n",
+		},
+		"indented": {
+			encoder: encoder.NewJSONEncoder(encoder.WithIndent("  "), encoder.WithEscapeHTML(false)),
+			want:    "{\n  \"link\": \"<a href=\\\"/?a=1&b=2\\\">\"\n}\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.encoder.New(&buf).Encode(src); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("body = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONDecoderUseNumber(t *testing.T) {
+	const payload = `{"id":9007199254740993}`
+
+	var precise map[string]any
+	if err := encoder.NewJSONDecoder(encoder.WithUseNumber()).New(strings.NewReader(payload)).Decode(&precise); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	number, ok := precise["id"].(json.Number)
+	if !ok {
+		t.Fatalf("id = %T, want json.Number", precise["id"])
+	}
+	if id, err := number.Int64(); err != nil || id != 9007199254740993 {
+		t.Errorf("id = %d, error %v, want 9007199254740993", id, err)
+	}
+
+	var lossy map[string]any
+	if err := encoder.JsonDecoder.New(strings.NewReader(payload)).Decode(&lossy); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if _, ok := lossy["id"].(float64); !ok {
+		t.Errorf("id = %T, want float64 by default", lossy["id"])
+	}
+}
+
+func TestJSONDecoderDisallowUnknownFields(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	const payload = `{"nmae":"bob"}`
+
+	var strict user
+	if err := encoder.NewJSONDecoder(encoder.WithDisallowUnknownFields()).New(strings.NewReader(payload)).Decode(&strict); err == nil {
+		t.Error("Decode() error = nil, want unknown field error")
+	}
+
+	var loose user
+	if err := encoder.JsonDecoder.New(strings.NewReader(payload)).Decode(&loose); err != nil {
+		t.Errorf("Decode() error = %v, want unknown field ignored by default", err)
+	}
+}
+
+// pointerMarshaler is marshaled by its method only when it is addressable
+type pointerMarshaler struct {
+	Name string
+}
+
+func (*pointerMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`"pointer"`), nil
+}
+
+func TestJSONEncoderEncodeTo(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type wrapper struct {
+		Inner pointerMarshaler `json:"inner"`
+	}
+
+	tests := map[string]any{
+		"struct":                  item{ID: 1, Name: "<b>"},
+		"pointer":                 &item{ID: 2},
+		"map":                     map[string]int{"a": 1},
+		"string":                  "text",
+		"nil":                     nil,
+		"pointer method":          pointerMarshaler{Name: "value"},
+		"field of pointer method": wrapper{Inner: pointerMarshaler{Name: "value"}},
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			var want, got bytes.Buffer
+			if err := encoder.JsonEncoder.New(&want).Encode(src); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			for range 2 {
+				got.Reset()
+				if err := encoder.JsonEncoder.(encoder.WriterEncoder).EncodeTo(&got, src); err != nil {
+					t.Fatalf("EncodeTo() error = %v", err)
+				}
+				if got.String() != want.String() {
+					t.Errorf("body = %q, want %q", got.String(), want.String())
+				}
+			}
+		})
+	}
+}
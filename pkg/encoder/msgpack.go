@@ -0,0 +1,50 @@
+package encoder
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackFallbackTag is used for struct fields without msgpack tag, so the same structures serve JSON and MessagePack
+const msgpackFallbackTag = "json"
+
+var MsgpackEncoder Encoder = &msgpackEncoder{}
+
+type msgpackEncoder struct {
+	encoder *msgpack.Encoder
+}
+
+func (e *msgpackEncoder) New(w io.Writer) Encoder {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag(msgpackFallbackTag)
+	return &msgpackEncoder{
+		encoder: encoder,
+	}
+}
+
+func (e *msgpackEncoder) Encode(src any) error {
+	return e.encoder.Encode(src)
+}
+
+func (e *msgpackEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+var MsgpackDecoder Decoder = &msgpackDecoder{}
+
+type msgpackDecoder struct {
+	decoder *msgpack.Decoder
+}
+
+func (d *msgpackDecoder) New(r io.Reader) Decoder {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag(msgpackFallbackTag)
+	return &msgpackDecoder{
+		decoder: decoder,
+	}
+}
+
+func (d *msgpackDecoder) Decode(dst any) error {
+	return d.decoder.Decode(dst)
+}
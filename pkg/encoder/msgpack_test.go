@@ -0,0 +1,55 @@
+package encoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encoder.MsgpackEncoder.New(&buf).Encode(user{ID: 1, Name: "bob"}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var fields map[string]any
+	if err := encoder.MsgpackDecoder.New(bytes.NewReader(buf.Bytes())).Decode(&fields); err != nil {
+		t.Fatalf("Decode() into map error = %v", err)
+	}
+	if _, ok := fields["name"]; !ok {
+		t.Errorf("keys of %v do not include json tag %q", fields, "name")
+	}
+
+	var got user
+	if err := encoder.MsgpackDecoder.New(&buf).Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if want := (user{ID: 1, Name: "bob"}); got != want {
+		t.Errorf("decoded = %+v, want %+v", got, want)
+	}
+	if got, want := contentType(encoder.MsgpackEncoder), "application/msgpack"; got != want {
+		t.Errorf("content type = %q, want %q", got, want)
+	}
+}
+
+// BenchmarkMsgpackSize reports size of a list response encoded as MessagePack and as JSON
+func BenchmarkMsgpackSize(b *testing.B) {
+	users := make([]user, 100)
+	for i := range users {
+		users[i] = user{ID: i, Name: "user"}
+	}
+
+	for name, e := range map[string]encoder.Encoder{"msgpack": encoder.MsgpackEncoder, "json": encoder.JsonEncoder} {
+		b.Run(name, func(b *testing.B) {
+			var buf bytes.Buffer
+			for range b.N {
+				buf.Reset()
+				if err := e.New(&buf).Encode(users); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(buf.Len()), "bytes/payload")
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package encoder
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+var NdjsonEncoder Encoder = &ndjsonEncoder{}
+
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonEncoder) New(w io.Writer) Encoder {
+	return &ndjsonEncoder{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Encode writes every element of slice or array src as a separate line, other values are written as a single line
+func (e *ndjsonEncoder) Encode(src any) error {
+	v := reflect.ValueOf(src)
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() == reflect.Uint8 {
+		return e.encoder.Encode(src)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encoder.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *ndjsonEncoder) ContentType() string {
+	return "application/x-ndjson; charset=utf-8"
+}
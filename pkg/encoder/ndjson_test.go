@@ -0,0 +1,43 @@
+package encoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+func TestNdjsonEncoder(t *testing.T) {
+	tests := map[string]struct {
+		src  any
+		want string
+	}{
+		"slice":  {src: []user{{ID: 1, Name: "bob"}, {ID: 2, Name: "alice"}}, want: "{\"id\":1,\"name\":\"bob\"}\n{\"id\":2,\"name\":\"alice\"}\n"},
+		"array":  {src: [2]int{1, 2}, want: "1\n2\n"},
+		"empty":  {src: []user{}, want: ""},
+		"single": {src: user{ID: 1, Name: "bob"}, want: "{\"id\":1,\"name\":\"bob\"}\n"},
+		"bytes":  {src: []byte("hi"), want: "\"aGk=\"\n"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encoder.NdjsonEncoder.New(&buf).Encode(tt.src); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("body = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNdjsonRegistered(t *testing.T) {
+	e, ok := encoder.NewRegistry().Lookup("application/x-ndjson")
+	if !ok {
+		t.Fatal("application/x-ndjson is not registered")
+	}
+	if got := contentType(e); got != "application/x-ndjson; charset=utf-8" {
+		t.Errorf("content type = %q, want %q", got, "application/x-ndjson; charset=utf-8")
+	}
+}
@@ -0,0 +1,23 @@
+package encoder
+
+import (
+	"io"
+)
+
+// Noop is an encoder writing nothing, e.g. for endpoints responding without a body.
+// Its content type is empty, so no Content-Type header is sent
+var Noop Encoder = noopEncoder{}
+
+type noopEncoder struct{}
+
+func (e noopEncoder) New(io.Writer) Encoder {
+	return e
+}
+
+func (noopEncoder) Encode(any) error {
+	return nil
+}
+
+func (noopEncoder) ContentType() string {
+	return ""
+}
@@ -0,0 +1,26 @@
+package encoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+func TestNoop(t *testing.T) {
+	var buf bytes.Buffer
+	enc := encoder.Noop.New(&buf)
+
+	for _, src := range []any{"hello", map[string]int{"a": 1}, nil} {
+		if err := enc.Encode(src); err != nil {
+			t.Fatalf("Encode(%v) error = %v", src, err)
+		}
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("written %d bytes, want 0", buf.Len())
+	}
+	if got := contentType(encoder.Noop); got != "" {
+		t.Errorf("ContentType() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,126 @@
+package encoder
+
+import (
+	"strings"
+	"sync"
+)
+
+var DefaultRegistry = NewRegistry()
+
+type Registry struct {
+	mu           sync.RWMutex
+	encoders     map[string]Encoder
+	contentTypes []string
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{
+		encoders: make(map[string]Encoder),
+	}
+
+	r.Register("application/json", JsonEncoder)
+	r.Register("application/xml", XmlEncoder)
+	r.Register("text/xml", XmlEncoder)
+	r.Register("application/yaml", YamlEncoder)
+	r.Register("application/msgpack", MsgpackEncoder)
+	r.Register("application/x-ndjson", NdjsonEncoder)
+
+	return r
+}
+
+func (r *Registry) Register(contentType string, e Encoder) {
+	contentType = normalizeContentType(contentType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.encoders[contentType]; !ok {
+		r.contentTypes = append(r.contentTypes, contentType)
+	}
+	r.encoders[contentType] = e
+}
+
+func (r *Registry) Lookup(contentType string) (Encoder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.encoders[normalizeContentType(contentType)]
+	return e, ok
+}
+
+func (r *Registry) ContentTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.contentTypes...)
+}
+
+func normalizeContentType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+var DefaultDecoderRegistry = NewDecoderRegistry()
+
+type DecoderRegistry struct {
+	mu           sync.RWMutex
+	decoders     map[string]Decoder
+	contentTypes []string
+}
+
+func NewDecoderRegistry() *DecoderRegistry {
+	r := &DecoderRegistry{
+		decoders: make(map[string]Decoder),
+	}
+
+	r.Register("application/json", JsonDecoder)
+	r.Register("application/xml", XmlDecoder)
+	r.Register("text/xml", XmlDecoder)
+	r.Register("application/msgpack", MsgpackDecoder)
+	r.Register("application/x-msgpack", MsgpackDecoder)
+
+	return r
+}
+
+func (r *DecoderRegistry) Register(contentType string, d Decoder) {
+	contentType = normalizeContentType(contentType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.decoders[contentType]; !ok {
+		r.contentTypes = append(r.contentTypes, contentType)
+	}
+	r.decoders[contentType] = d
+}
+
+// Lookup returns decoder registered for content type, types with +json and +xml suffixes
+// fall back to application/json and application/xml decoders
+func (r *DecoderRegistry) Lookup(contentType string) (Decoder, bool) {
+	contentType = normalizeContentType(contentType)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if d, ok := r.decoders[contentType]; ok {
+		return d, true
+	}
+
+	switch {
+	case strings.HasSuffix(contentType, "+json"):
+		d, ok := r.decoders["application/json"]
+		return d, ok
+	case strings.HasSuffix(contentType, "+xml"):
+		d, ok := r.decoders["application/xml"]
+		return d, ok
+	}
+
+	return nil, false
+}
+
+func (r *DecoderRegistry) ContentTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.contentTypes...)
+}
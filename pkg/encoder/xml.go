@@ -3,20 +3,90 @@ package encoder
 import (
 	"encoding/xml"
 	"io"
+	"reflect"
 )
 
-var XmlEncoder Encoder = &xmlEncoder{}
+var XmlEncoder = NewXMLEncoder()
 
 type xmlEncoder struct {
-	encoder *xml.Encoder
+	w           io.Writer
+	encoder     *xml.Encoder
+	root        string
+	wroteHeader bool
+}
+
+type XMLOption func(e *xmlEncoder)
+
+// WithRootElement sets name of the root element wrapping slices and arrays, which have no root element themselves.
+// Default value is "items"
+func WithRootElement(name string) XMLOption {
+	return func(e *xmlEncoder) {
+		e.root = name
+	}
+}
+
+func NewXMLEncoder(options ...XMLOption) Encoder {
+	var e = xmlEncoder{root: "items"}
+	for _, opt := range options {
+		opt(&e)
+	}
+
+	return &e
 }
 
 func (e *xmlEncoder) New(w io.Writer) Encoder {
 	return &xmlEncoder{
+		w:       w,
 		encoder: xml.NewEncoder(w),
+		root:    e.root,
 	}
 }
 
 func (e *xmlEncoder) Encode(src any) error {
-	return e.encoder.Encode(src)
+	if !e.wroteHeader {
+		e.wroteHeader = true
+		if _, err := io.WriteString(e.w, xml.Header); err != nil {
+			return err
+		}
+	}
+
+	v := reflect.ValueOf(src)
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() == reflect.Uint8 {
+		return e.encoder.Encode(src)
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: e.root}}
+	if err := e.encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encoder.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	if err := e.encoder.EncodeToken(start.End()); err != nil {
+		return err
+	}
+
+	return e.encoder.Flush()
+}
+
+func (e *xmlEncoder) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+var XmlDecoder Decoder = &xmlDecoder{}
+
+type xmlDecoder struct {
+	decoder *xml.Decoder
+}
+
+func (d *xmlDecoder) New(r io.Reader) Decoder {
+	return &xmlDecoder{
+		decoder: xml.NewDecoder(r),
+	}
+}
+
+func (d *xmlDecoder) Decode(dst any) error {
+	return d.decoder.Decode(dst)
 }
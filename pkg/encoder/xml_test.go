@@ -0,0 +1,73 @@
+package encoder_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+type book struct {
+	XMLName xml.Name `xml:"book"`
+	Title   string   `xml:"title"`
+}
+
+func TestXMLEncoder(t *testing.T) {
+	tests := map[string]struct {
+		encoder encoder.Encoder
+		src     any
+		want    string
+	}{
+		"struct": {
+			encoder: encoder.XmlEncoder,
+			src:     book{Title: "Go"},
+			want:    xml.Header + "<book><title>Go</title></book>",
+		},
+		"slice in default root": {
+			encoder: encoder.XmlEncoder,
+			src:     []book{{Title: "Go"}, {Title: "C"}},
+			want:    xml.Header + "<items><book><title>Go</title></book><book><title>C</title></book></items>",
+		},
+		"slice in custom root": {
+			encoder: encoder.NewXMLEncoder(encoder.WithRootElement("books")),
+			src:     []book{{Title: "Go"}},
+			want:    xml.Header + "<books><book><title>Go</title></book></books>",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.encoder.New(&buf).Encode(tt.src); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("body = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestXMLEncoderHeaderOnce(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		enc = encoder.XmlEncoder.New(&buf)
+	)
+
+	for _, title := range []string{"Go", "C"} {
+		if err := enc.Encode(book{Title: title}); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+
+	if want := xml.Header + "<book><title>Go</title></book><book><title>C</title></book>"; buf.String() != want {
+		t.Errorf("body = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestXMLEncoderContentType(t *testing.T) {
+	if got := contentType(encoder.XmlEncoder); got != "application/xml; charset=utf-8" {
+		t.Errorf("ContentType() = %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+package encoder
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+var YamlEncoder Encoder = &yamlEncoder{}
+
+type yamlEncoder struct {
+	encoder *yaml.Encoder
+}
+
+func (e *yamlEncoder) New(w io.Writer) Encoder {
+	return &yamlEncoder{
+		encoder: yaml.NewEncoder(w),
+	}
+}
+
+func (e *yamlEncoder) Encode(src any) error {
+	return e.encoder.Encode(src)
+}
+
+func (e *yamlEncoder) ContentType() string {
+	return "application/yaml; charset=utf-8"
+}
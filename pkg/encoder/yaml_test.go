@@ -0,0 +1,35 @@
+package encoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+type user struct {
+	ID   int    `json:"id" yaml:"id" xml:"id" csv:"id"`
+	Name string `json:"name" yaml:"name" xml:"name" csv:"name"`
+}
+
+// contentType returns content type reported by e, empty when e does not report it
+func contentType(e encoder.Encoder) string {
+	if contentTyper, ok := e.(encoder.ContentTyper); ok {
+		return contentTyper.ContentType()
+	}
+	return ""
+}
+
+func TestYamlEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encoder.YamlEncoder.New(&buf).Encode(user{ID: 1, Name: "bob"}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	if want := "id: 1\nname: bob\n"; buf.String() != want {
+		t.Errorf("body = %q, want %q", buf.String(), want)
+	}
+	if got, want := contentType(encoder.YamlEncoder), "application/yaml; charset=utf-8"; got != want {
+		t.Errorf("content type = %q, want %q", got, want)
+	}
+}
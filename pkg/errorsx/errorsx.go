@@ -1,11 +1,38 @@
 package errorsx
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const maxStackDepth = 32
+
+// Sentinel errors to match errors by code with [errors.Is], e.g. errors.Is(err, errorsx.ErrNotFound).
+// They have no message. Builders like WithErrorCode return copies, so errorsx.ErrNotFound.WithErrorCode("USER_NOT_FOUND")
+// is safe and keeps the sentinel intact
+var (
+	ErrBadRequest          = New(false, http.StatusBadRequest, "")
+	ErrUnauthorized        = New(false, http.StatusUnauthorized, "")
+	ErrForbidden           = New(false, http.StatusForbidden, "")
+	ErrNotFound            = New(false, http.StatusNotFound, "")
+	ErrConflict            = New(false, http.StatusConflict, "")
+	ErrUnprocessableEntity = New(false, http.StatusUnprocessableEntity, "")
+	ErrTooManyRequests     = New(false, http.StatusTooManyRequests, "")
+	ErrInternal            = New(true, http.StatusInternalServerError, "")
+	ErrServiceUnavailable  = New(false, http.StatusServiceUnavailable, "")
+)
 
 type Errorx struct {
 	code       int
 	isInternal bool
 	message    string
+	stack      []uintptr
+	retryAfter time.Duration
+	errorCode  string
 }
 
 func (e *Errorx) Error() string {
@@ -20,6 +47,101 @@ func (e *Errorx) Code() int {
 	return e.code
 }
 
+// Is reports whether target is Errorx with the same code and internal flag. Message and error code are compared
+// only when target has them, so sentinel errors match any message
+func (e *Errorx) Is(target error) bool {
+	t, ok := target.(*Errorx)
+	if !ok || t == nil {
+		return false
+	}
+
+	return e.code == t.code && e.isInternal == t.isInternal && (t.message == "" || e.message == t.message) &&
+		(t.errorCode == "" || e.errorCode == t.errorCode)
+}
+
+// WithErrorCode sets machine-readable error code, e.g. USER_NOT_FOUND, so clients can branch on it instead of message.
+// Handlers send errors with code as [ErrorResponse]: {"code":"USER_NOT_FOUND","status":404,"message":"user not found"}.
+// It returns a copy of e
+func (e *Errorx) WithErrorCode(code string) *Errorx {
+	c := *e
+	c.errorCode = code
+	return &c
+}
+
+// ErrorCode returns error code set by WithErrorCode
+func (e *Errorx) ErrorCode() string {
+	return e.errorCode
+}
+
+// An ErrorResponse is a serialized form of Errorx with error code
+type ErrorResponse struct {
+	Code    string `json:"code,omitempty" xml:"code,omitempty"`
+	Status  int    `json:"status" xml:"status"`
+	Message string `json:"message" xml:"message"`
+}
+
+// Response returns serialized form of the error
+func (e *Errorx) Response() ErrorResponse {
+	return ErrorResponse{Code: e.errorCode, Status: e.code, Message: e.message}
+}
+
+// Err restores not internal error from its serialized form, e.g. in API clients
+func (r ErrorResponse) Err() *Errorx {
+	return New(false, r.Status, r.Message).WithErrorCode(r.Code)
+}
+
+// WithRetryAfter sets duration after which client can retry the request, it is sent in Retry-After header.
+// Use it with [http.StatusTooManyRequests] and [http.StatusServiceUnavailable] errors. It returns a copy of e
+func (e *Errorx) WithRetryAfter(d time.Duration) *Errorx {
+	c := *e
+	c.retryAfter = d
+	return &c
+}
+
+func (e *Errorx) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// WithStack records call stack of the caller, so it is logged with internal errors. Capturing stack has a cost,
+// use it for unexpected errors only. It returns a copy of e
+func (e *Errorx) WithStack() *Errorx {
+	pcs := make([]uintptr, maxStackDepth)
+	c := *e
+	c.stack = pcs[:runtime.Callers(2, pcs)]
+	return &c
+}
+
+// StackTrace returns call stack recorded with WithStack formatted as function and file:line pairs,
+// empty string is returned when stack was not recorded
+func (e *Errorx) StackTrace() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+
+	var (
+		b      strings.Builder
+		frames = runtime.CallersFrames(e.stack)
+	)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+// Equal reports whether a and b have the same code, internal flag, message and error code. Stack and retry duration are ignored
+func Equal(a, b *Errorx) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.code == b.code && a.isInternal == b.isInternal && a.message == b.message && a.errorCode == b.errorCode
+}
+
 func New(isInternal bool, code int, message string) *Errorx {
 	return &Errorx{
 		isInternal: isInternal,
@@ -28,8 +150,71 @@ func New(isInternal bool, code int, message string) *Errorx {
 	}
 }
 
+// As finds Errorx in err chain. For errors joined with [errors.Join] the most severe one is returned:
+// any internal error wins, otherwise error with the highest code is returned with messages of all joined errors.
+// Joined errors which are not Errorx are treated as internal ones, as handlers treat plain errors
 func As(err error) (*Errorx, bool) {
-	var rErr *Errorx
-	ok := errors.As(err, &rErr)
-	return rErr, ok
+	if err == nil {
+		return nil, false
+	}
+
+	var (
+		joined []*Errorx
+		found  bool
+	)
+	collect(err, &joined, &found)
+
+	if !found {
+		var rErr *Errorx
+		ok := errors.As(err, &rErr)
+		return rErr, ok
+	}
+	if len(joined) == 1 {
+		return joined[0], true
+	}
+
+	var (
+		severe   = joined[0]
+		messages = make([]string, 0, len(joined))
+	)
+	for _, e := range joined {
+		if e.isInternal {
+			return e, true
+		}
+		if e.code > severe.code {
+			severe = e
+		}
+		messages = append(messages, e.message)
+	}
+
+	return &Errorx{
+		code:       severe.code,
+		message:    strings.Join(messages, "; "),
+		stack:      severe.stack,
+		retryAfter: severe.retryAfter,
+		errorCode:  severe.errorCode,
+	}, true
+}
+
+// collect appends the first Errorx of every branch of err tree to errs, branches ending with other errors
+// are appended as internal errors. Found reports whether any Errorx is in the tree
+func collect(err error, errs *[]*Errorx, found *bool) {
+	switch e := err.(type) {
+	case nil:
+	case *Errorx:
+		*errs = append(*errs, e)
+		*found = true
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			collect(err, errs, found)
+		}
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			collect(inner, errs, found)
+			return
+		}
+		*errs = append(*errs, New(true, http.StatusInternalServerError, err.Error()))
+	default:
+		*errs = append(*errs, New(true, http.StatusInternalServerError, err.Error()))
+	}
 }
@@ -0,0 +1,157 @@
+package errorsx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+)
+
+func TestErrorCodeRoundTrip(t *testing.T) {
+	err := errorsx.New(false, http.StatusNotFound, "user not found").WithErrorCode("USER_NOT_FOUND")
+
+	data, mErr := json.Marshal(err.Response())
+	if mErr != nil {
+		t.Fatal(mErr)
+	}
+	if want := `{"code":"USER_NOT_FOUND","status":404,"message":"user not found"}`; string(data) != want {
+		t.Fatalf("response = %s, want %s", data, want)
+	}
+
+	var resp errorsx.ErrorResponse
+	if uErr := json.Unmarshal(data, &resp); uErr != nil {
+		t.Fatal(uErr)
+	}
+	if got := resp.Err(); !errorsx.Equal(got, err) {
+		t.Errorf("round trip = %+v, want %+v", got.Response(), err.Response())
+	}
+}
+
+func TestBuildersReturnCopies(t *testing.T) {
+	base := errorsx.New(false, http.StatusTooManyRequests, "slow down")
+
+	coded := base.WithErrorCode("RATE_LIMITED")
+	delayed := base.WithRetryAfter(time.Minute)
+	stacked := base.WithStack()
+
+	if base.ErrorCode() != "" || base.RetryAfter() != 0 || base.StackTrace() != "" {
+		t.Fatalf("base error was modified: code %q, retry after %s", base.ErrorCode(), base.RetryAfter())
+	}
+	if coded.ErrorCode() != "RATE_LIMITED" || delayed.RetryAfter() != time.Minute || stacked.StackTrace() == "" {
+		t.Error("builders did not set their values on the copies")
+	}
+	if !errors.Is(coded, base) {
+		t.Error("copy with error code does not match its base")
+	}
+}
+
+func TestSentinels(t *testing.T) {
+	notFound := errorsx.ErrNotFound.WithErrorCode("USER_NOT_FOUND").WithRetryAfter(time.Second)
+
+	for name, tc := range map[string]struct {
+		err    error
+		target error
+		want   bool
+	}{
+		"same code":          {errorsx.New(false, http.StatusNotFound, "user not found"), errorsx.ErrNotFound, true},
+		"other code":         {errorsx.New(false, http.StatusConflict, "exists"), errorsx.ErrNotFound, false},
+		"internal flag":      {errorsx.New(false, http.StatusInternalServerError, "oops"), errorsx.ErrInternal, false},
+		"wrapped":            {fmt.Errorf("get user: %w", errorsx.New(false, http.StatusNotFound, "x")), errorsx.ErrNotFound, true},
+		"derived sentinel":   {notFound, errorsx.ErrNotFound, true},
+		"derived as target":  {errorsx.New(false, http.StatusNotFound, "x"), notFound, false},
+		"after derived copy": {errorsx.New(false, http.StatusNotFound, "x"), errorsx.ErrNotFound, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := errors.Is(tc.err, tc.target); got != tc.want {
+				t.Errorf("errors.Is = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if errorsx.ErrNotFound.ErrorCode() != "" || errorsx.ErrNotFound.RetryAfter() != 0 {
+		t.Error("sentinel was modified by builders")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := errorsx.New(false, http.StatusBadRequest, "bad")
+	if !errorsx.Equal(a, errorsx.New(false, http.StatusBadRequest, "bad").WithStack()) {
+		t.Error("errors differing only by stack are not equal")
+	}
+	if errorsx.Equal(a, errorsx.New(false, http.StatusBadRequest, "other")) {
+		t.Error("errors with different messages are equal")
+	}
+	if !errorsx.Equal(nil, nil) || errorsx.Equal(a, nil) {
+		t.Error("nil handling is wrong")
+	}
+}
+
+func TestAsJoined(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err      error
+		code     int
+		internal bool
+		message  string
+	}{
+		"highest code wins": {
+			err:     errors.Join(errorsx.New(false, http.StatusBadRequest, "bad name"), errorsx.New(false, http.StatusNotFound, "no group")),
+			code:    http.StatusNotFound,
+			message: "bad name; no group",
+		},
+		"internal wins": {
+			err:      errors.Join(errorsx.New(false, http.StatusBadRequest, "bad name"), errorsx.New(true, http.StatusInternalServerError, "db down")),
+			code:     http.StatusInternalServerError,
+			internal: true,
+			message:  "db down",
+		},
+		"plain error is internal": {
+			err:      errors.Join(errorsx.New(false, http.StatusBadRequest, "bad name"), errors.New("db down")),
+			code:     http.StatusInternalServerError,
+			internal: true,
+			message:  "db down",
+		},
+		"wrapped single": {
+			err:     fmt.Errorf("create user: %w", errorsx.New(false, http.StatusConflict, "exists")),
+			code:    http.StatusConflict,
+			message: "exists",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			errx, ok := errorsx.As(tc.err)
+			if !ok {
+				t.Fatal("Errorx not found")
+			}
+			if errx.Code() != tc.code || errx.Internal() != tc.internal || errx.Error() != tc.message {
+				t.Errorf("As = %d internal %v %q, want %d internal %v %q",
+					errx.Code(), errx.Internal(), errx.Error(), tc.code, tc.internal, tc.message)
+			}
+		})
+	}
+
+	if _, ok := errorsx.As(errors.Join(errors.New("a"), errors.New("b"))); ok {
+		t.Error("Errorx found in join of plain errors")
+	}
+	if _, ok := errorsx.As(nil); ok {
+		t.Error("Errorx found in nil error")
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	plain := errorsx.New(true, http.StatusInternalServerError, "db is down")
+	if stack := plain.StackTrace(); stack != "" {
+		t.Errorf("StackTrace() without WithStack = %q, want empty", stack)
+	}
+
+	stack := plain.WithStack().StackTrace()
+	if !strings.Contains(stack, "errorsx_test.TestWithStack") || !strings.Contains(stack, "errorsx_test.go:") {
+		t.Errorf("StackTrace() = %q, want frame of the caller", stack)
+	}
+	if strings.Contains(stack, "errorsx.(*Errorx).WithStack") {
+		t.Errorf("StackTrace() = %q, want WithStack itself skipped", stack)
+	}
+}
@@ -0,0 +1,63 @@
+package errorsx
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+type ValidationError struct {
+	Message string       `json:"message" xml:"message"`
+	Fields  []FieldError `json:"fields,omitempty" xml:"fields>field,omitempty"`
+
+	code int
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return e.Message + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return New(false, e.Code(), e.Error())
+}
+
+// WithCode sets status code of the error, default value is [http.StatusUnprocessableEntity]. It returns a copy of e
+func (e *ValidationError) WithCode(code int) *ValidationError {
+	c := *e
+	c.code = code
+	return &c
+}
+
+func (e *ValidationError) Code() int {
+	if e.code == 0 {
+		return http.StatusUnprocessableEntity
+	}
+	return e.code
+}
+
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{
+		Message: "validation failed",
+		Fields:  fields,
+	}
+}
+
+func AsValidation(err error) (*ValidationError, bool) {
+	var vErr *ValidationError
+	ok := errors.As(err, &vErr)
+	return vErr, ok
+}
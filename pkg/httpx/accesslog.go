@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// An AccessLogFormat is a format of lines written by AccessLog middleware
+type AccessLogFormat int
+
+const (
+	// FormatCommon is an Apache Common Log Format followed by duration of the request in microseconds:
+	// 127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 1532
+	FormatCommon AccessLogFormat = iota
+	// FormatCombined is an Apache Combined Log Format, Common Log Format followed by referer and user agent,
+	// then duration of the request in microseconds
+	FormatCombined
+)
+
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLog returns middleware writing a text line in given format to w for every request, for log ingestion tools
+// which do not parse structured logs. Writes are serialized, so w does not need to be safe for concurrent use
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithMiddleware(httpx.AccessLog(os.Stdout, httpx.FormatCombined)))
+func AccessLog(w io.Writer, format AccessLogFormat) Middleware {
+	var mu sync.Mutex
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			var (
+				start = time.Now()
+				rec   = NewResponseRecorder(rw)
+			)
+
+			next.ServeHTTP(rec, r)
+
+			line := formatAccessLog(r, rec, start, time.Since(start), format)
+
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = io.WriteString(w, line)
+		})
+	}
+}
+
+func formatAccessLog(r *http.Request, rec *ResponseRecorder, start time.Time, duration time.Duration, format AccessLogFormat) string {
+	user := "-"
+	if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	} else if username, _, ok := r.BasicAuth(); ok && username != "" {
+		user = username
+	}
+
+	size := "-"
+	if rec.BytesWritten() > 0 {
+		size = strconv.FormatInt(rec.BytesWritten(), 10)
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+		remoteIP(r),
+		user,
+		start.Format(accessLogTimeFormat),
+		r.Method,
+		r.URL.RequestURI(),
+		r.Proto,
+		rec.Status(),
+		size,
+	)
+
+	if format == FormatCombined {
+		line += fmt.Sprintf(" %s %s", quoteOrDash(r.Referer()), quoteOrDash(r.UserAgent()))
+	}
+
+	return line + " " + strconv.FormatInt(duration.Microseconds(), 10) + "\n"
+}
+
+func quoteOrDash(s string) string {
+	if s == "" {
+		return `"-"`
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,64 @@
+package httpx_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestAccessLog(t *testing.T) {
+	const common = `^192\.0\.2\.1 - frank \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "POST /items\?page=2 HTTP/1\.1" 201 7`
+
+	tests := map[string]struct {
+		format httpx.AccessLogFormat
+		want   string
+	}{
+		"common": {
+			format: httpx.FormatCommon,
+			want:   common + ` \d+\n$`,
+		},
+		"combined": {
+			format: httpx.FormatCombined,
+			want:   common + ` "https://example\.com/" "test-agent/1\.0" \d+\n$`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			handler := httpx.AccessLog(&logs, tt.format)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = io.WriteString(w, "created")
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/items?page=2", nil)
+			r.SetBasicAuth("frank", "secret")
+			r.Header.Set("Referer", "https://example.com/")
+			r.Header.Set("User-Agent", "test-agent/1.0")
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if !regexp.MustCompile(tt.want).MatchString(logs.String()) {
+				t.Errorf("line = %q, want match of %q", logs.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLogWithoutBody(t *testing.T) {
+	var logs bytes.Buffer
+	handler := httpx.AccessLog(&logs, httpx.FormatCombined)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+
+	want := `^192\.0\.2\.1 - - \[[^\]]+\] "DELETE /items/1 HTTP/1\.1" 204 - "-" "-" \d+\n$`
+	if !regexp.MustCompile(want).MatchString(logs.String()) {
+		t.Errorf("line = %q, want match of %q", logs.String(), want)
+	}
+}
@@ -0,0 +1,65 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+)
+
+// BearerAuth returns middleware authenticating requests with token from Authorization: Bearer header.
+// Context returned by verify is passed to the wrapped handler, so use cases can read authenticated user from it.
+//
+// Requests without token or with token rejected by verify get [http.StatusUnauthorized] with WWW-Authenticate header.
+// When verify returns [errorsx.Errorx], its code and message are used instead, e.g. [http.StatusForbidden]
+// for valid tokens without access
+//
+// Usage:
+//
+//	httpx.WithMiddleware(httpx.BearerAuth(func(ctx context.Context, token string) (context.Context, error) {
+//		user, err := auth.Verify(ctx, token)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return context.WithValue(ctx, userKey{}, user), nil
+//	}))
+func BearerAuth(verify func(ctx context.Context, token string) (context.Context, error)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			token = strings.TrimSpace(token)
+			if !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeDefaultResponse(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+				return
+			}
+
+			ctx, err := verify(r.Context(), token)
+			if err != nil {
+				if errx, ok := errorsx.As(err); ok {
+					if errx.Internal() {
+						writeDefaultResponse(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+						return
+					}
+					if errx.Code() == http.StatusUnauthorized {
+						w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					}
+					setRetryAfter(w, errx)
+					writeDefaultResponse(w, errx.Code(), errx.Error())
+					return
+				}
+
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeDefaultResponse(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+				return
+			}
+
+			if ctx == nil {
+				ctx = r.Context()
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
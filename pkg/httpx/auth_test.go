@@ -0,0 +1,95 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+var subjectKey = httpx.NewContextKey[string]("subject")
+
+// verifyToken accepts token "valid" as user bob, token "readonly" is valid but has no access
+func verifyToken(ctx context.Context, token string) (context.Context, error) {
+	switch token {
+	case "valid":
+		return subjectKey.WithValue(ctx, "bob"), nil
+	case "readonly":
+		return nil, errorsx.New(false, http.StatusForbidden, "no access")
+	default:
+		return nil, errors.New("unknown token")
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	handler := httpx.Handle(func(ctx context.Context, _ emptyRequest) (string, error) {
+		subject, _ := subjectKey.Value(ctx)
+		return subject, nil
+	}, httpx.WithMiddleware(httpx.BearerAuth(verifyToken)))
+
+	tests := map[string]struct {
+		authorization string
+		code          int
+		authenticate  string
+		body          string
+	}{
+		"missing header": {
+			code:         http.StatusUnauthorized,
+			authenticate: "Bearer",
+			body:         `{"message":"Unauthorized"}` + "\n",
+		},
+		"other scheme": {
+			authorization: "Basic Ym9iOnNlY3JldA==",
+			code:          http.StatusUnauthorized,
+			authenticate:  "Bearer",
+			body:          `{"message":"Unauthorized"}` + "\n",
+		},
+		"empty token": {
+			authorization: "Bearer ",
+			code:          http.StatusUnauthorized,
+			authenticate:  "Bearer",
+			body:          `{"message":"Unauthorized"}` + "\n",
+		},
+		"rejected token": {
+			authorization: "Bearer stolen",
+			code:          http.StatusUnauthorized,
+			authenticate:  `Bearer error="invalid_token"`,
+			body:          `{"message":"Unauthorized"}` + "\n",
+		},
+		"forbidden": {
+			authorization: "Bearer readonly",
+			code:          http.StatusForbidden,
+			body:          `{"message":"no access"}` + "\n",
+		},
+		"valid token": {
+			authorization: "bearer valid",
+			code:          http.StatusOK,
+			body:          `"bob"` + "\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, want %d", w.Code, tt.code)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.authenticate {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.authenticate)
+			}
+			if got := w.Body.String(); got != tt.body {
+				t.Errorf("body = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
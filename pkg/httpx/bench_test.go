@@ -0,0 +1,203 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// discardWriter is a reusable [http.ResponseWriter], so benchmarks measure allocations of the handler only
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w *discardWriter) WriteHeader(int) {}
+
+type benchResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func benchmarkHandle(b *testing.B, r *http.Request, options ...httpx.Option) {
+	handler := httpx.Handle[emptyRequest, benchResponse](func(context.Context, emptyRequest) (benchResponse, error) {
+		return benchResponse{ID: 1, Name: "item"}, nil
+	}, append([]httpx.Option{httpx.WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil)))}, options...)...)
+
+	w := &discardWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		clear(w.header)
+		handler(w, r)
+	}
+}
+
+func BenchmarkHandle(b *testing.B) {
+	b.Run("default", func(b *testing.B) {
+		benchmarkHandle(b, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	})
+	b.Run("request id header", func(b *testing.B) {
+		r := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		r.Header.Set(httpx.RequestIDHeader, "c0ffee")
+		benchmarkHandle(b, r)
+	})
+	b.Run("without request id", func(b *testing.B) {
+		benchmarkHandle(b, httptest.NewRequest(http.MethodGet, "/items/1", nil), httpx.WithoutRequestID())
+	})
+}
+
+func TestWithoutRequestID(t *testing.T) {
+	var (
+		logs bytes.Buffer
+		id   string
+		ok   bool
+	)
+	handler := httpx.Handle[emptyRequest, string](func(ctx context.Context, _ emptyRequest) (string, error) {
+		id, ok = httpx.RequestIDFromContext(ctx)
+		return "", errors.New("db is down")
+	}, httpx.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))), httpx.WithoutRequestID())
+
+	w := serve(handler, http.MethodGet, "/")
+
+	if ok || id != "" {
+		t.Errorf("RequestIDFromContext() = %q, %v, want no id", id, ok)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(w.Body.String(), "request_id") {
+		t.Errorf("body = %q, want no request id", w.Body.String())
+	}
+
+	var completed bool
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("unmarshal log %q: %v", line, err)
+		}
+		if record["msg"] == "completed" {
+			completed = true
+			if record["status"] != float64(http.StatusInternalServerError) {
+				t.Errorf("completed record = %v, want flat status", record)
+			}
+		}
+	}
+	if !completed {
+		t.Errorf("logs = %q, want completed record", logs.String())
+	}
+}
+
+func TestGeneratedRequestID(t *testing.T) {
+	var id string
+	handler := httpx.Handle[emptyRequest, string](func(ctx context.Context, _ emptyRequest) (string, error) {
+		id, _ = httpx.RequestIDFromContext(ctx)
+		return "ok", nil
+	})
+	serve(handler, http.MethodGet, "/")
+
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		t.Fatalf("request id %q is not uuid: %v", id, err)
+	}
+	if parsed.Version() != 4 || parsed.Variant() != uuid.RFC4122 {
+		t.Errorf("request id %q has version %d and variant %v, want random uuid", id, parsed.Version(), parsed.Variant())
+	}
+}
+
+func TestRequestIDFromHeader(t *testing.T) {
+	var id string
+	handler := httpx.Handle[emptyRequest, string](func(ctx context.Context, _ emptyRequest) (string, error) {
+		id, _ = httpx.RequestIDFromContext(ctx)
+		return "ok", nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(httpx.RequestIDHeader, "c0ffee")
+	handler(httptest.NewRecorder(), r)
+
+	if id != "c0ffee" {
+		t.Errorf("request id = %q, want %q", id, "c0ffee")
+	}
+}
+
+func TestIDGenerator(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   string
+		calls  int
+	}{
+		"generated":        {want: "id-1", calls: 1},
+		"header preferred": {header: "c0ffee", want: "c0ffee"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				logs  bytes.Buffer
+				calls int
+				id    string
+			)
+			generate := func() string {
+				calls++
+				return "id-" + strconv.Itoa(calls)
+			}
+			handler := httpx.Handle[emptyRequest, string](func(ctx context.Context, _ emptyRequest) (string, error) {
+				id, _ = httpx.RequestIDFromContext(ctx)
+				return "", errors.New("db is down")
+			}, httpx.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))), httpx.WithIDGenerator(generate))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(httpx.RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if calls != tt.calls {
+				t.Errorf("generator called %d times, want %d", calls, tt.calls)
+			}
+			if id != tt.want {
+				t.Errorf("request id in context = %q, want %q", id, tt.want)
+			}
+
+			var body httpx.DefaultResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", w.Body.String(), err)
+			}
+			if body.RequestID != tt.want {
+				t.Errorf("request id in response = %q, want %q", body.RequestID, tt.want)
+			}
+
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				var record map[string]json.RawMessage
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("log line %q: %v", line, err)
+				}
+				if _, ok := record[tt.want]; !ok {
+					t.Errorf("log line %q is not grouped by %q", line, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,647 @@
+package httpx
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+)
+
+const (
+	jsonTag    = "json"
+	xmlTag     = "xml"
+	msgpackTag = "msgpack"
+	queryTag   = "query"
+	pathTag    = "path"
+	headerTag  = "header"
+	formTag    = "form"
+	fileTag    = "file"
+)
+
+// BindJSON decodes JSON body of [http.Request] into dst. An empty body is not treated as an error.
+// Returns [http.StatusBadRequest] error when the body is malformed
+func BindJSON(r *http.Request, dst any) error {
+	return bindBody(r, encoder.JsonDecoder, dst)
+}
+
+// BindBody decodes body of [http.Request] into dst with decoder, e.g. JSON decoder with custom options.
+// An empty body is not treated as an error. Returns [http.StatusBadRequest] error when the body is malformed
+//
+// Usage:
+//
+//	var decoder = encoder.NewJSONDecoder(encoder.WithUseNumber(), encoder.WithDisallowUnknownFields())
+//
+//	func (req *Request) Bind(r *http.Request) error {
+//		return httpx.BindBody(r, decoder, req)
+//	}
+func BindBody(r *http.Request, decoder encoder.Decoder, dst any) error {
+	return bindBody(r, decoder, dst)
+}
+
+// BindXML decodes XML body of [http.Request] into dst. An empty body is not treated as an error.
+// Returns [http.StatusBadRequest] error when the body is malformed
+func BindXML(r *http.Request, dst any) error {
+	return bindBody(r, encoder.XmlDecoder, dst)
+}
+
+// BindMsgpack decodes MessagePack body of [http.Request] into dst. An empty body is not treated as an error.
+// Returns [http.StatusBadRequest] error when the body is malformed
+func BindMsgpack(r *http.Request, dst any) error {
+	return bindBody(r, encoder.MsgpackDecoder, dst)
+}
+
+// BindForm parses URL-encoded form body and URL query of [http.Request] and maps struct fields tagged with `form:"name"`
+// into dst. Body values take precedence over query values. Conversion rules and `required` option are the same as in BindQuery.
+// Returns [http.StatusBadRequest] error when the form is malformed or conversion fails
+func BindForm(r *http.Request, dst any) error {
+	if err := r.ParseForm(); err != nil {
+		if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+			return errorsx.New(false, http.StatusRequestEntityTooLarge, maxBytesErr.Error())
+		}
+		return errorsx.New(false, http.StatusBadRequest, fmt.Sprintf("invalid form: %v", err))
+	}
+
+	return bindValues(dst, formTag, lookupValues(r, r.Form))
+}
+
+// BindMultipart parses multipart/form-data body of [http.Request] keeping up to maxMemory bytes of files in memory,
+// the rest is stored in temporary files. Scalar fields are mapped by `form:"name"` tag as in BindForm,
+// fields tagged with `file:"name"` receive uploaded files and must be of *[multipart.FileHeader] or []*[multipart.FileHeader] type.
+// Combine it with WithMaxBodySize to limit the size of the whole upload.
+// Returns [http.StatusBadRequest] error when the body is malformed and [http.StatusRequestEntityTooLarge] when it is too large
+//
+// Usage:
+//
+//	type UploadRequest struct {
+//		httpx.DefaultRequest
+//		Title  string                `form:"title,required"`
+//		Avatar *multipart.FileHeader `file:"avatar,required"`
+//	}
+//
+//	func (req *UploadRequest) Bind(r *http.Request) error {
+//		return httpx.BindMultipart(r, req, 32<<20)
+//	}
+//
+// Uploaded file is opened with req.Avatar.Open() inside the use case
+func BindMultipart(r *http.Request, dst any, maxMemory int64) error {
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+			return errorsx.New(false, http.StatusRequestEntityTooLarge, maxBytesErr.Error())
+		}
+		if errors.Is(err, multipart.ErrMessageTooLarge) {
+			return errorsx.New(false, http.StatusRequestEntityTooLarge, err.Error())
+		}
+		return errorsx.New(false, http.StatusBadRequest, fmt.Sprintf("invalid multipart form: %v", err))
+	}
+
+	// r.Form of multipart request lists query values before body values, body values take precedence as in BindForm
+	values := make(url.Values, len(r.Form))
+	for key, value := range r.PostForm {
+		values[key] = append(values[key], value...)
+	}
+	for key, value := range r.URL.Query() {
+		values[key] = append(values[key], value...)
+	}
+
+	if err := bindValues(dst, formTag, lookupValues(r, values)); err != nil {
+		return err
+	}
+
+	return bindFiles(reflect.ValueOf(dst).Elem(), r.MultipartForm.File)
+}
+
+func bindFiles(v reflect.Value, files map[string][]*multipart.FileHeader) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		options, ok := parseTag(field.Tag.Get(fileTag))
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := bindFiles(v.Field(i), files); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		headers := files[options.name]
+		if len(headers) == 0 {
+			if options.required {
+				return errorsx.New(false, http.StatusBadRequest, fmt.Sprintf("missing required %s %q", fileTag, options.name))
+			}
+			continue
+		}
+
+		switch field.Type {
+		case reflect.TypeFor[*multipart.FileHeader]():
+			v.Field(i).Set(reflect.ValueOf(headers[0]))
+		case reflect.TypeFor[[]*multipart.FileHeader]():
+			v.Field(i).Set(reflect.ValueOf(headers))
+		default:
+			return errorsx.New(true, http.StatusInternalServerError, fmt.Sprintf("cannot bind %s %q into %s", fileTag, options.name, field.Type))
+		}
+	}
+
+	return nil
+}
+
+// BindAll binds body, path, query and header values of [http.Request] into dst in sequence. Body errors are returned
+// immediately, missing and malformed path, query and header values are reported together in one [errorsx.ValidationError].
+// Body is decoded with decoder registered in [encoder.DefaultDecoderRegistry] for Content-Type: JSON for application/json,
+// XML for application/xml or text/xml, MessagePack for application/msgpack, form for application/x-www-form-urlencoded.
+// Body without Content-Type is decoded as JSON, unsupported types are rejected with [http.StatusUnsupportedMediaType].
+// Body step is skipped when the request has no body or dst has no fields tagged for the decoder.
+//
+// Usage:
+//
+//	type Request struct {
+//		httpx.DefaultRequest
+//		ID     int    `path:"id"`
+//		Name   string `json:"name"`
+//		Tenant string `header:"X-Tenant-ID,required"`
+//	}
+//
+//	func (req *Request) Bind(r *http.Request) error {
+//		return httpx.BindAll(r, req)
+//	}
+func BindAll(r *http.Request, dst any) error {
+	if hasBody(r) {
+		if err := bindBodyOf(r, dst); err != nil {
+			return err
+		}
+	}
+
+	var errs []*errorsx.ValidationError
+	for _, bind := range []func(*http.Request, any) error{BindPath, BindQuery, BindHeader} {
+		err := bind(r, dst)
+		if err == nil {
+			continue
+		}
+		vErr, ok := errorsx.AsValidation(err)
+		if !ok {
+			return err
+		}
+		errs = append(errs, vErr)
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	joined := &errorsx.ValidationError{Message: "invalid request"}
+	for _, vErr := range errs {
+		joined.Fields = append(joined.Fields, vErr.Fields...)
+	}
+	return joined.WithCode(http.StatusBadRequest)
+}
+
+// bindBodyOf decodes body of [http.Request] into dst with decoder registered in [encoder.DefaultDecoderRegistry]
+// for Content-Type header, body without Content-Type is decoded as JSON.
+// Returns [http.StatusUnsupportedMediaType] error when no decoder is registered
+func bindBodyOf(r *http.Request, dst any) error {
+	contentType := mediaType(r.Header.Get("Content-Type"))
+	if contentType == "application/x-www-form-urlencoded" {
+		if !hasTag(reflect.TypeOf(dst), formTag) {
+			return nil
+		}
+		return BindForm(r, dst)
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	decoder, ok := encoder.DefaultDecoderRegistry.Lookup(contentType)
+	if !ok {
+		return errorsx.New(false, http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type %q", contentType))
+	}
+
+	var tags []string
+	switch decoder {
+	case encoder.JsonDecoder:
+		tags = []string{jsonTag}
+	case encoder.XmlDecoder:
+		tags = []string{xmlTag}
+	case encoder.MsgpackDecoder:
+		tags = []string{msgpackTag, jsonTag}
+	}
+
+	if len(tags) > 0 && !hasTag(reflect.TypeOf(dst), tags...) {
+		return nil
+	}
+
+	return bindBody(r, decoder, dst)
+}
+
+var (
+	strictBodyKey = NewContextKey[bool]("strict body")
+
+	strictJSONDecoder = encoder.NewJSONDecoder(encoder.WithDisallowUnknownFields())
+)
+
+func bindBody(r *http.Request, decoder encoder.Decoder, dst any) error {
+	if !hasBody(r) {
+		return nil
+	}
+
+	strict, _ := strictBodyKey.Value(r.Context())
+	if strict && decoder == encoder.JsonDecoder {
+		decoder = strictJSONDecoder
+	}
+
+	err := decoder.New(r.Body).Decode(dst)
+	if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+		return errorsx.New(false, http.StatusRequestEntityTooLarge, maxBytesErr.Error())
+	}
+	if field, ok := unknownField(err); ok {
+		return errorsx.New(false, http.StatusBadRequest, fmt.Sprintf("invalid request body: unknown field %s", field))
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return errorsx.New(false, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+	}
+
+	return nil
+}
+
+// unknownField returns quoted key reported by JSON decoder with disallowed unknown fields,
+// encoding/json has no typed error for it
+func unknownField(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	return strings.CutPrefix(err.Error(), "json: unknown field ")
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+func mediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+func hasTag(t reflect.Type, tags ...string) bool {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		for _, tag := range tags {
+			if _, ok := field.Tag.Lookup(tag); ok {
+				return true
+			}
+		}
+		if field.Anonymous && hasTag(field.Type, tags...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BindQuery maps struct fields tagged with `query:"name"` from the URL query of [http.Request] into dst.
+// Add `required` option to the tag to reject requests without the value: `query:"name,required"`.
+//
+// Supported field types are strings, booleans, integers, floats, types implementing [encoding.TextUnmarshaler],
+// pointers to them and slices of them. Repeated values are bound into a slice field.
+// [time.Time] fields are parsed as RFC 3339 unless layout is set with `format` option: `query:"from,format=2006-01-02"`,
+// [time.Duration] fields are parsed with [time.ParseDuration].
+// Allowed values of string and integer fields are listed with `oneof` option: `query:"status,oneof=active inactive"`,
+// other values are rejected. Values are compared after conversion, so `oneof=1 2` accepts 01 for integer fields.
+// Returns [http.StatusBadRequest] [errorsx.ValidationError] listing every field with missing required value
+// or failed conversion, so clients can fix all of them at once
+func BindQuery(r *http.Request, dst any) error {
+	return bindValues(dst, queryTag, lookupValues(r, r.URL.Query()))
+}
+
+// BindPath maps struct fields tagged with `path:"name"` from the path wildcards of [http.Request] into dst.
+// Conversion rules and `required` option are the same as in BindQuery
+func BindPath(r *http.Request, dst any) error {
+	return bindValues(dst, pathTag, func(key string) []string {
+		value := r.PathValue(key)
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	})
+}
+
+// BindHeader maps struct fields tagged with `header:"X-Tenant-ID"` from the headers of [http.Request] into dst.
+// Conversion rules and `required` option are the same as in BindQuery, repeated headers are bound into a slice field.
+//
+// Binders can be combined inside one Bind method of your request.
+//
+// Usage:
+//
+//	func (req *Request) Bind(r *http.Request) error {
+//		if err := httpx.BindJSON(r, req); err != nil {
+//			return err
+//		}
+//		if err := httpx.BindPath(r, req); err != nil {
+//			return err
+//		}
+//		if err := httpx.BindQuery(r, req); err != nil {
+//			return err
+//		}
+//		return httpx.BindHeader(r, req)
+//	}
+func BindHeader(r *http.Request, dst any) error {
+	return bindValues(dst, headerTag, func(key string) []string {
+		return r.Header.Values(key)
+	})
+}
+
+var keyNormalizerKey = NewContextKey[func(string) string]("key normalizer")
+
+// NormalizeKey lowercases key and drops underscores and dashes, so snake_case, kebab-case, camelCase and PascalCase
+// spellings of the key are the same
+func NormalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, key)
+}
+
+// lookupValues returns lookup of values by key, keys are also matched in normalized form when
+// normalizer is set with WithKeyNormalizer
+func lookupValues(r *http.Request, values url.Values) func(key string) []string {
+	normalize, ok := keyNormalizerKey.Value(r.Context())
+	if !ok || normalize == nil {
+		return func(key string) []string {
+			return values[key]
+		}
+	}
+
+	normalized := make(map[string][]string, len(values))
+	for key, value := range values {
+		key = normalize(key)
+		normalized[key] = append(normalized[key], value...)
+	}
+
+	return func(key string) []string {
+		if value, ok := values[key]; ok {
+			return value
+		}
+		return normalized[normalize(key)]
+	}
+}
+
+type tagOptions struct {
+	name     string
+	required bool
+	format   string
+	oneof    []string
+}
+
+func parseTag(tag string) (tagOptions, bool) {
+	if tag == "" || tag == "-" {
+		return tagOptions{}, false
+	}
+
+	parts := strings.Split(tag, ",")
+	options := tagOptions{name: strings.TrimSpace(parts[0])}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "required" {
+			options.required = true
+		}
+		if format, ok := strings.CutPrefix(part, "format="); ok {
+			options.format = format
+		}
+		if oneof, ok := strings.CutPrefix(part, "oneof="); ok {
+			options.oneof = strings.Fields(oneof)
+		}
+	}
+
+	return options, options.name != ""
+}
+
+// bindValues binds values into dst, missing required values and failed conversions of all fields are reported
+// together as [http.StatusBadRequest] validation error. Fields of unsupported types are reported as internal error
+func bindValues(dst any, tag string, lookup func(key string) []string) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errorsx.New(true, http.StatusInternalServerError, fmt.Sprintf("cannot bind %s into %T", tag, dst))
+	}
+
+	var fields []errorsx.FieldError
+	if err := bindStruct(v.Elem(), tag, lookup, &fields); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return (&errorsx.ValidationError{
+		Message: "invalid " + tag,
+		Fields:  fields,
+	}).WithCode(http.StatusBadRequest)
+}
+
+func bindStruct(v reflect.Value, tag string, lookup func(key string) []string, fields *[]errorsx.FieldError) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		options, ok := parseTag(field.Tag.Get(tag))
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := bindStruct(v.Field(i), tag, lookup, fields); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		values := lookup(options.name)
+		if len(values) == 0 {
+			if options.required {
+				*fields = append(*fields, errorsx.FieldError{Field: options.name, Message: "is required"})
+			}
+			continue
+		}
+
+		if !oneOf(field.Type, options, values) {
+			*fields = append(*fields, errorsx.FieldError{
+				Field:   options.name,
+				Message: "must be one of " + strings.Join(options.oneof, ", "),
+			})
+			continue
+		}
+
+		if err := setField(v.Field(i), values, options.format); err != nil {
+			if errors.Is(err, errUnsupportedType) {
+				return errorsx.New(true, http.StatusInternalServerError, fmt.Sprintf("cannot bind %s %q into %s", tag, options.name, field.Type))
+			}
+			*fields = append(*fields, errorsx.FieldError{Field: options.name, Message: err.Error()})
+		}
+	}
+
+	return nil
+}
+
+// oneOf reports whether all values are allowed by `oneof` option. Values are compared after conversion to the type
+// of field t, so 01 matches 1 for integer fields. Values failing conversion are left for setField to report
+func oneOf(t reflect.Type, options tagOptions, values []string) bool {
+	if len(options.oneof) == 0 {
+		return true
+	}
+
+	if t.Kind() == reflect.Slice && !reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		t = t.Elem()
+	}
+
+	convert := func(value string) (any, bool) {
+		v := reflect.New(t).Elem()
+		if err := setValue(v, value, options.format); err != nil {
+			return nil, false
+		}
+		for v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		return v.Interface(), true
+	}
+
+	for _, value := range values {
+		converted, ok := convert(value)
+		if !ok {
+			continue
+		}
+		if !slices.ContainsFunc(options.oneof, func(allowed string) bool {
+			a, ok := convert(allowed)
+			return ok && reflect.DeepEqual(a, converted)
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func setField(v reflect.Value, values []string, format string) error {
+	if v.Kind() == reflect.Slice && !implementsTextUnmarshaler(v) {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value, format); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	return setValue(v, values[0], format)
+}
+
+func implementsTextUnmarshaler(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+func setValue(v reflect.Value, value, format string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), value, format)
+	}
+
+	switch v.Type() {
+	case reflect.TypeFor[time.Time]():
+		if format == "" {
+			format = time.RFC3339
+		}
+		t, err := time.Parse(format, value)
+		if err != nil {
+			return fmt.Errorf("expected time in %q format", format)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case reflect.TypeFor[time.Duration]():
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("expected duration like 1h30m")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if implementsTextUnmarshaler(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("expected true or false")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return numberError("integer", err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return numberError("unsigned integer", err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return numberError("number", err)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("%w %s", errUnsupportedType, v.Type())
+	}
+
+	return nil
+}
+
+// errUnsupportedType is returned by setValue for types it cannot convert values into, it is a bug of request type
+// rather than of the client
+var errUnsupportedType = errors.New("unsupported type")
+
+// numberError describes failed number conversion without internals of [strconv.NumError]
+func numberError(kind string, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%s out of range", kind)
+	}
+	return fmt.Errorf("expected %s", kind)
+}
@@ -0,0 +1,397 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestBindQueryOneOf(t *testing.T) {
+	type query struct {
+		Status string `query:"status,oneof=active inactive"`
+		Level  int    `query:"level,oneof=1 2"`
+		Levels []int  `query:"levels,oneof=1 2"`
+	}
+
+	tests := map[string]struct {
+		target string
+		valid  bool
+	}{
+		"allowed string":         {target: "/?status=active", valid: true},
+		"rejected string":        {target: "/?status=deleted"},
+		"allowed integer":        {target: "/?level=2", valid: true},
+		"integer with leading 0": {target: "/?level=01", valid: true},
+		"rejected integer":       {target: "/?level=3"},
+		"allowed slice":          {target: "/?levels=1&levels=02", valid: true},
+		"rejected slice element": {target: "/?levels=1&levels=3"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var dst query
+			err := httpx.BindQuery(httptest.NewRequest("GET", tt.target, nil), &dst)
+			if tt.valid && err != nil {
+				t.Fatalf("BindQuery() error = %v", err)
+			}
+			if !tt.valid {
+				var validation *errorsx.ValidationError
+				if !errors.As(err, &validation) {
+					t.Fatalf("BindQuery() error = %v, want ValidationError", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBindQueryOneOfConversionError(t *testing.T) {
+	var dst struct {
+		Level int `query:"level,oneof=1 2"`
+	}
+
+	err := httpx.BindQuery(httptest.NewRequest("GET", "/?level=abc", nil), &dst)
+	var validation *errorsx.ValidationError
+	if !errors.As(err, &validation) || len(validation.Fields) != 1 {
+		t.Fatalf("BindQuery() error = %v, want one field error", err)
+	}
+	if got := validation.Fields[0].Message; got == "must be one of 1, 2" {
+		t.Errorf("message = %q, want conversion error", got)
+	}
+}
+
+// msgpackBody returns v encoded as MessagePack
+func msgpackBody(v any) string {
+	var buf bytes.Buffer
+	_ = encoder.MsgpackEncoder.New(&buf).Encode(v)
+	return buf.String()
+}
+
+func TestBindMsgpack(t *testing.T) {
+	type body struct {
+		ID    int    `json:"id"`
+		Title string `msgpack:"heading" json:"title"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(msgpackBody(map[string]any{"id": 7, "heading": "x"})))
+	r.Header.Set("Content-Type", "application/msgpack")
+
+	var dst body
+	if err := httpx.BindMsgpack(r, &dst); err != nil {
+		t.Fatalf("BindMsgpack() error = %v", err)
+	}
+	if want := (body{ID: 7, Title: "x"}); dst != want {
+		t.Errorf("bound = %+v, want %+v", dst, want)
+	}
+}
+
+func TestBindAllBody(t *testing.T) {
+	type body struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	tests := map[string]struct {
+		contentType string
+		body        string
+		want        string
+		code        int
+	}{
+		"json":              {contentType: "application/json", body: `{"name":"x"}`, want: "x"},
+		"json with charset": {contentType: "application/json; charset=utf-8", body: `{"name":"x"}`, want: "x"},
+		"no content type":   {body: `{"name":"x"}`, want: "x"},
+		"xml":               {contentType: "application/xml", body: "<body><name>x</name></body>", want: "x"},
+		"text xml":          {contentType: "text/xml; charset=utf-8", body: "<body><name>x</name></body>", want: "x"},
+		"unsupported":       {contentType: "text/plain", body: "x", code: http.StatusUnsupportedMediaType},
+		"unsupported empty": {contentType: "text/plain"},
+		"malformed json":    {contentType: "application/json", body: `{"name":`, code: http.StatusBadRequest},
+		"msgpack":           {contentType: "application/msgpack", body: msgpackBody(map[string]string{"name": "x"}), want: "x"},
+		"x-msgpack":         {contentType: "application/x-msgpack", body: msgpackBody(map[string]string{"name": "x"}), want: "x"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var dst body
+			err := httpx.BindAll(r, &dst)
+			if tt.code != 0 {
+				errx, ok := errorsx.As(err)
+				if !ok || errx.Code() != tt.code {
+					t.Fatalf("BindAll() error = %v, want code %d", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BindAll() error = %v", err)
+			}
+			if dst.Name != tt.want {
+				t.Errorf("Name = %q, want %q", dst.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindQueryTime(t *testing.T) {
+	type query struct {
+		From    time.Time     `query:"from"`
+		Day     *time.Time    `query:"day,format=2006-01-02"`
+		Timeout time.Duration `query:"timeout"`
+	}
+
+	tests := map[string]struct {
+		target  string
+		want    query
+		message string
+	}{
+		"rfc3339":          {target: "/?from=2024-01-01T10:00:00Z", want: query{From: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}},
+		"custom layout":    {target: "/?day=2024-02-03", want: query{Day: ptr(time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC))}},
+		"duration":         {target: "/?timeout=1h30m", want: query{Timeout: 90 * time.Minute}},
+		"invalid time":     {target: "/?from=yesterday", message: `expected time in "2006-01-02T15:04:05Z07:00" format`},
+		"wrong layout":     {target: "/?day=2024-02-03T00:00:00Z", message: `expected time in "2006-01-02" format`},
+		"invalid duration": {target: "/?timeout=soon", message: "expected duration like 1h30m"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var dst query
+			err := httpx.BindQuery(httptest.NewRequest("GET", tt.target, nil), &dst)
+			if tt.message != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.message) {
+					t.Fatalf("BindQuery() error = %v, want %q", err, tt.message)
+				}
+				if errx, ok := errorsx.As(err); !ok || errx.Code() != http.StatusBadRequest {
+					t.Errorf("BindQuery() error = %v, want code %d", err, http.StatusBadRequest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BindQuery() error = %v", err)
+			}
+			if !dst.From.Equal(tt.want.From) || dst.Timeout != tt.want.Timeout ||
+				(dst.Day == nil) != (tt.want.Day == nil) || (dst.Day != nil && !dst.Day.Equal(*tt.want.Day)) {
+				t.Errorf("BindQuery() = %+v, want %+v", dst, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// pageRequest is bound from query
+type pageRequest struct {
+	httpx.DefaultRequest
+	PerPage int `query:"per_page"`
+}
+
+func (r *pageRequest) Bind(req *http.Request) error {
+	return httpx.BindQuery(req, r)
+}
+
+func (r pageRequest) String() string {
+	return strconv.Itoa(r.PerPage)
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	perPage := func(_ context.Context, req pageRequest) (int, error) {
+		return req.PerPage, nil
+	}
+	loose := httpx.Handle(perPage, httpx.WithKeyNormalizer(httpx.NormalizeKey))
+	strict := httpx.Handle(perPage)
+
+	tests := map[string]struct {
+		handler http.Handler
+		target  string
+		want    string
+	}{
+		"snake case":           {handler: loose, target: "/?per_page=10", want: "10"},
+		"camel case":           {handler: loose, target: "/?perPage=10", want: "10"},
+		"pascal case":          {handler: loose, target: "/?PerPage=10", want: "10"},
+		"exact match wins":     {handler: loose, target: "/?perPage=5&per_page=10", want: "10"},
+		"strict by default":    {handler: strict, target: "/?perPage=10", want: "0"},
+		"strict exact matches": {handler: strict, target: "/?per_page=10", want: "10"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %q", w.Code, w.Body.String())
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+				t.Errorf("per page = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindBodyUnknownField(t *testing.T) {
+	var (
+		dst struct {
+			Name string `json:"name"`
+		}
+		decoder = encoder.NewJSONDecoder(encoder.WithDisallowUnknownFields())
+		r       = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"nmae":"bob"}`))
+	)
+
+	err := httpx.BindBody(r, decoder, &dst)
+	if errx, ok := errorsx.As(err); !ok || errx.Code() != http.StatusBadRequest {
+		t.Errorf("BindBody() error = %v, want code %d", err, http.StatusBadRequest)
+	}
+}
+
+// tenantRequest is bound from query and header with BindAll
+type tenantRequest struct {
+	httpx.DefaultRequest
+	Page    int    `query:"page,required"`
+	PerPage int    `query:"per_page,required"`
+	Tenant  string `header:"X-Tenant-ID,required"`
+}
+
+func (r *tenantRequest) Bind(req *http.Request) error {
+	return httpx.BindAll(req, r)
+}
+
+func (r tenantRequest) String() string {
+	return r.Tenant
+}
+
+func TestBindAllReportsAllFields(t *testing.T) {
+	handler := httpx.Handle(func(context.Context, tenantRequest) (string, error) {
+		return "ok", nil
+	})
+
+	tests := map[string]struct {
+		target string
+		tenant string
+		want   string
+	}{
+		"two missing query values": {
+			target: "/",
+			tenant: "acme",
+			want:   `{"message":"invalid query","fields":[{"field":"page","message":"is required"},{"field":"per_page","message":"is required"}]}` + "\n",
+		},
+		"query and header": {
+			target: "/?page=x&per_page=10",
+			want:   `{"message":"invalid request","fields":[{"field":"page","message":"expected integer"},{"field":"X-Tenant-ID","message":"is required"}]}` + "\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.tenant != "" {
+				r.Header.Set("X-Tenant-ID", tt.tenant)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindFormBodyPrecedence(t *testing.T) {
+	type form struct {
+		Name  string `form:"name"`
+		Scope string `form:"scope"`
+	}
+
+	contentType, multipartBody := multipartBody(t, map[string]string{"name": "body"}, nil)
+	tests := map[string]struct {
+		contentType string
+		body        string
+		bind        func(*http.Request, any) error
+	}{
+		"urlencoded": {
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=body",
+			bind:        httpx.BindForm,
+		},
+		"multipart": {
+			contentType: contentType,
+			body:        multipartBody.String(),
+			bind: func(r *http.Request, dst any) error {
+				return httpx.BindMultipart(r, dst, 1<<20)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/?name=query&scope=query", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			var dst form
+			if err := tt.bind(r, &dst); err != nil {
+				t.Fatalf("bind error = %v", err)
+			}
+			if want := (form{Name: "body", Scope: "query"}); dst != want {
+				t.Errorf("bound = %+v, want %+v", dst, want)
+			}
+		})
+	}
+}
+
+type countRequest struct {
+	httpx.DefaultRequest
+	Count int `form:"count"`
+}
+
+func (r *countRequest) Bind(req *http.Request) error {
+	return httpx.BindForm(req, r)
+}
+
+func (r countRequest) String() string {
+	return strconv.Itoa(r.Count)
+}
+
+func TestBindFormInvalidValue(t *testing.T) {
+	handler := httpx.Handle(func(context.Context, countRequest) (string, error) {
+		return "ok", nil
+	})
+
+	w := post(handler, "/", "application/x-www-form-urlencoded", strings.NewReader("count=many"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if got, want := w.Body.String(), `{"message":"invalid form","fields":[{"field":"count","message":"expected integer"}]}`+"\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestBindUnsupportedFieldType(t *testing.T) {
+	type query struct {
+		Filter map[string]string `query:"filter"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?filter=a", nil)
+	err := httpx.BindQuery(r, &query{})
+
+	errx, ok := errorsx.As(err)
+	if !ok || !errx.Internal() || errx.Code() != http.StatusInternalServerError {
+		t.Fatalf("BindQuery() error = %v, want internal error", err)
+	}
+	if _, ok := errorsx.AsValidation(err); ok {
+		t.Errorf("BindQuery() error = %v is validation error, want internal", err)
+	}
+}
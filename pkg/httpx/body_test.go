@@ -0,0 +1,221 @@
+package httpx_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	handler := httpx.Handle[userRequest, string](greet, httpx.WithMaxBodySize(16))
+
+	tests := map[string]struct {
+		body io.Reader
+		want int
+	}{
+		"within limit":            {body: strings.NewReader(`{"name":"bob"}`), want: http.StatusOK},
+		"declared length too big": {body: strings.NewReader(`{"name":"` + strings.Repeat("a", 32) + `"}`), want: http.StatusRequestEntityTooLarge},
+		// io.MultiReader hides the length, so the body is limited while being read
+		"unknown length too big": {body: io.MultiReader(strings.NewReader(`{"name":"` + strings.Repeat("a", 32) + `"}`)), want: http.StatusRequestEntityTooLarge},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := post(handler, "/", "application/json", tt.body)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d, body %q", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAcceptedContentTypes(t *testing.T) {
+	var called bool
+	handler := httpx.Handle[userRequest, string](func(ctx context.Context, req userRequest) (string, error) {
+		called = true
+		return greet(ctx, req)
+	}, httpx.WithAcceptedContentTypes("Application/JSON"))
+
+	tests := map[string]struct {
+		contentType string
+		body        io.Reader
+		want        int
+	}{
+		"accepted":       {contentType: "application/json", body: strings.NewReader(`{"name":"bob"}`), want: http.StatusOK},
+		"with charset":   {contentType: "application/json; charset=utf-8", body: strings.NewReader(`{"name":"bob"}`), want: http.StatusOK},
+		"unsupported":    {contentType: "text/plain", body: strings.NewReader(`bob`), want: http.StatusUnsupportedMediaType},
+		"missing header": {body: strings.NewReader(`{"name":"bob"}`), want: http.StatusUnsupportedMediaType},
+		"without body":   {want: http.StatusOK},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			called = false
+			body := tt.body
+			if body == nil {
+				body = http.NoBody
+			}
+			w := post(handler, "/", tt.contentType, body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body %q", w.Code, tt.want, w.Body.String())
+			}
+			if wantCalled := tt.want == http.StatusOK; called != wantCalled {
+				t.Errorf("use case called = %v, want %v", called, wantCalled)
+			}
+		})
+	}
+}
+
+func TestMaxBodySizeExpectContinue(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(httpx.Handle[userRequest, string](func(ctx context.Context, req userRequest) (string, error) {
+		called = true
+		return greet(ctx, req)
+	}, httpx.WithMaxBodySize(16)))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	// body is never sent, server has to answer right after headers
+	_, err = io.WriteString(conn, "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Type: application/json\r\n"+
+		"Content-Length: 1048576\r\nExpect: 100-continue\r\n\r\n")
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+	if called {
+		t.Error("use case called, want request rejected")
+	}
+}
+
+func TestStrictBody(t *testing.T) {
+	strict := httpx.Handle[userRequest, string](greet, httpx.WithStrictBody())
+
+	tests := map[string]struct {
+		handler http.Handler
+		body    string
+		code    int
+		want    string
+	}{
+		"unknown field": {handler: strict, body: `{"name":"bob","nmae":"bob"}`, code: http.StatusBadRequest, want: `{"message":"invalid request body: unknown field \"nmae\""}` + "\n"},
+		"known fields":  {handler: strict, body: `{"name":"bob"}`, code: http.StatusOK, want: `"hello bob"` + "\n"},
+		"lenient":       {handler: httpx.Handle[userRequest, string](greet), body: `{"name":"bob","nmae":"bob"}`, code: http.StatusOK, want: `"hello bob"` + "\n"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := post(tt.handler, "/", "application/json", strings.NewReader(tt.body))
+			if w.Code != tt.code {
+				t.Errorf("status = %d, want %d", w.Code, tt.code)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictBodyIgnoresQuery(t *testing.T) {
+	handler := httpx.Handle(func(_ context.Context, req pageRequest) (int, error) {
+		return req.PerPage, nil
+	}, httpx.WithStrictBody())
+
+	if w := serve(handler, http.MethodGet, "/?per_page=10&unknown=1"); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+// linesRequest reads raw body with BindStream and requires at least one line
+type linesRequest struct {
+	httpx.DefaultRequest
+	raw   string
+	lines int
+}
+
+func (r *linesRequest) BindStream(_ context.Context, body io.Reader) error {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	r.raw = string(raw)
+	r.lines = strings.Count(r.raw, "\n")
+	return nil
+}
+
+func (r *linesRequest) Validate() error {
+	if r.lines == 0 {
+		return errorsx.New(false, http.StatusUnprocessableEntity, "no lines")
+	}
+	return nil
+}
+
+func (r linesRequest) String() string {
+	return r.raw
+}
+
+func TestBindStream(t *testing.T) {
+	handler := httpx.Handle(func(_ context.Context, req linesRequest) (string, error) {
+		return req.raw, nil
+	}, httpx.WithMaxBodySize(32))
+
+	tests := map[string]struct {
+		body io.Reader
+		code int
+		want string
+	}{
+		"raw body": {
+			body: strings.NewReader(`{"id":1}` + "\n" + `{"id":2` + "\n"),
+			code: http.StatusOK,
+			want: `"{\"id\":1}\n{\"id\":2\n"` + "\n",
+		},
+		"validated after bind": {
+			body: strings.NewReader("no newline"),
+			code: http.StatusUnprocessableEntity,
+			want: `"no lines"` + "\n",
+		},
+		"declared length too big": {
+			body: strings.NewReader(strings.Repeat("a\n", 32)),
+			code: http.StatusRequestEntityTooLarge,
+		},
+		// io.MultiReader hides the length, so the body is limited while BindStream reads it
+		"unknown length too big": {
+			body: io.MultiReader(strings.NewReader(strings.Repeat("a\n", 32))),
+			code: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := post(handler, "/", "application/x-ndjson", tt.body)
+			if w.Code != tt.code {
+				t.Errorf("status = %d, want %d, body %q", w.Code, tt.code, w.Body.String())
+			}
+			if tt.want != "" && w.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
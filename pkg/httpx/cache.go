@@ -0,0 +1,50 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A CachePolicy describes caching of successful response
+type CachePolicy struct {
+	// MaxAge is a duration for which the response is fresh
+	MaxAge time.Duration
+	// Private forbids caching in shared caches like CDNs and proxies
+	Private bool
+	// NoStore forbids caching at all, MaxAge and Private are ignored
+	NoStore bool
+}
+
+// A Cacheable is implemented by responses which can be cached. Handle sets Cache-Control and Expires headers
+// of successful response from the returned policy
+type Cacheable interface {
+	CachePolicy() CachePolicy
+}
+
+// setCacheHeaders sets Cache-Control and Expires headers when response implements Cacheable
+func setCacheHeaders(w http.ResponseWriter, response any) {
+	cacheable, ok := response.(Cacheable)
+	if !ok {
+		return
+	}
+
+	policy := cacheable.CachePolicy()
+	if policy.NoStore {
+		w.Header().Set("Cache-Control", "no-store")
+		return
+	}
+
+	visibility := "public"
+	if policy.Private {
+		visibility = "private"
+	}
+
+	maxAge := int(policy.MaxAge / time.Second)
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	w.Header().Set("Cache-Control", visibility+", max-age="+strconv.Itoa(maxAge))
+	w.Header().Set("Expires", time.Now().Add(time.Duration(maxAge)*time.Second).UTC().Format(http.TimeFormat))
+}
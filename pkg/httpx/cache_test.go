@@ -0,0 +1,90 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type cachedItem struct {
+	Name   string `json:"name"`
+	policy httpx.CachePolicy
+}
+
+func (i cachedItem) CachePolicy() httpx.CachePolicy {
+	return i.policy
+}
+
+func TestCacheable(t *testing.T) {
+	tests := map[string]struct {
+		policy       httpx.CachePolicy
+		cacheControl string
+		expires      time.Duration
+	}{
+		"max age": {
+			policy:       httpx.CachePolicy{MaxAge: time.Minute},
+			cacheControl: "public, max-age=60",
+			expires:      time.Minute,
+		},
+		"private": {
+			policy:       httpx.CachePolicy{MaxAge: 90 * time.Second, Private: true},
+			cacheControl: "private, max-age=90",
+			expires:      90 * time.Second,
+		},
+		"no store": {
+			policy:       httpx.CachePolicy{MaxAge: time.Hour, Private: true, NoStore: true},
+			cacheControl: "no-store",
+		},
+		"negative max age": {
+			policy:       httpx.CachePolicy{MaxAge: -time.Minute},
+			cacheControl: "public, max-age=0",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := httpx.HandleNoReq(func(context.Context) (cachedItem, error) {
+				return cachedItem{Name: "a", policy: tt.policy}, nil
+			})
+
+			w := serve(handler, http.MethodGet, "/items/1")
+			if got := w.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.cacheControl)
+			}
+
+			expires := w.Header().Get("Expires")
+			if tt.policy.NoStore {
+				if expires != "" {
+					t.Errorf("Expires = %q, want none with no-store", expires)
+				}
+				return
+			}
+			at, err := http.ParseTime(expires)
+			if err != nil {
+				t.Fatalf("Expires = %q: %v", expires, err)
+			}
+			if want := time.Now().Add(tt.expires); at.Before(want.Add(-2*time.Second)) || at.After(want.Add(time.Second)) {
+				t.Errorf("Expires = %v, want about %v", at, want)
+			}
+		})
+	}
+}
+
+func TestCacheableError(t *testing.T) {
+	handler := httpx.HandleNoReq(func(context.Context) (cachedItem, error) {
+		return cachedItem{policy: httpx.CachePolicy{MaxAge: time.Hour}}, errNotFound
+	})
+
+	w := serve(handler, http.MethodGet, "/items/1")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	for _, header := range []string{"Cache-Control", "Expires"} {
+		if got := w.Header().Get(header); got != "" {
+			t.Errorf("%s = %q, want none for error response", header, got)
+		}
+	}
+}
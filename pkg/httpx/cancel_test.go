@@ -0,0 +1,57 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestClientClosedRequest(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		recorded    int
+		handler     = httpx.Handle(func(ctx context.Context, _ emptyRequest) (string, error) {
+			cancel()
+			return "", ctx.Err()
+		}, httpx.WithMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec := httpx.NewResponseRecorder(w)
+				next.ServeHTTP(rec, r)
+				recorded = rec.Status()
+			})
+		}))
+	)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	if recorded != httpx.StatusClientClosedRequest {
+		t.Errorf("recorded status = %d, want %d", recorded, httpx.StatusClientClosedRequest)
+	}
+	if w.Code == httpx.StatusClientClosedRequest || w.Body.Len() > 0 {
+		t.Errorf("client got status %d and %d bytes, want nothing sent", w.Code, w.Body.Len())
+	}
+}
+
+func TestTimeoutResponseBody(t *testing.T) {
+	handler := httpx.Handle(func(ctx context.Context, _ emptyRequest) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, httpx.WithTimeout(10*time.Millisecond))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(httpx.RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got, want := w.Body.String(), `{"message":"Service Unavailable","request_id":"req-1"}`+"\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+// A ConcurrencyOverflow defines what happens with requests arriving when concurrency limit of the handler is reached
+type ConcurrencyOverflow int
+
+const (
+	// ConcurrencyWait blocks request until a slot is released or request context is done
+	ConcurrencyWait ConcurrencyOverflow = iota
+	// ConcurrencyReject rejects request immediately with [http.StatusServiceUnavailable]
+	ConcurrencyReject
+)
+
+// concurrencyHandler runs next handler only when a slot of semaphore is acquired. Slot is released when next returns or panics
+func concurrencyHandler(semaphore chan struct{}, overflow ConcurrencyOverflow, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if overflow == ConcurrencyReject {
+			select {
+			case semaphore <- struct{}{}:
+			default:
+				writeDefaultResponse(w, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+				return
+			}
+		} else {
+			select {
+			case semaphore <- struct{}{}:
+			case <-r.Context().Done():
+				allowFinalWrite(w)
+				writeDefaultResponse(w, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+				return
+			}
+		}
+		defer func() { <-semaphore }()
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,97 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// blocking returns handler whose use case reports start to started and waits for release
+func blocking(started chan<- struct{}, release <-chan struct{}, options ...httpx.Option) http.HandlerFunc {
+	return httpx.Handle[emptyRequest, string](func(context.Context, emptyRequest) (string, error) {
+		started <- struct{}{}
+		<-release
+		return "ok", nil
+	}, options...)
+}
+
+func TestConcurrencyReject(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := blocking(started, release, httpx.WithConcurrencyLimit(1), httpx.WithConcurrencyOverflow(httpx.ConcurrencyReject))
+
+	done := make(chan int)
+	go func() { done <- serve(handler, http.MethodGet, "/").Code }()
+	<-started
+
+	if w := serve(handler, http.MethodGet, "/"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("request over limit status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("first request status = %d, want %d", code, http.StatusOK)
+	}
+
+	go func() { <-started }()
+	if w := serve(handler, http.MethodGet, "/"); w.Code != http.StatusOK {
+		t.Errorf("request after release status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestConcurrencyWait(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := blocking(started, release, httpx.WithConcurrencyLimit(1))
+
+	first := make(chan int)
+	go func() { first <- serve(handler, http.MethodGet, "/").Code }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("request with expired context status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got, want := w.Body.String(), `{"message":"Service Unavailable"}`+"\n"; got != want {
+		t.Errorf("request with expired context body = %q, want %q", got, want)
+	}
+
+	second := make(chan int)
+	go func() { second <- serve(handler, http.MethodGet, "/").Code }()
+
+	close(release)
+	<-started
+	for name, ch := range map[string]chan int{"first": first, "waiting": second} {
+		if code := <-ch; code != http.StatusOK {
+			t.Errorf("%s request status = %d, want %d", name, code, http.StatusOK)
+		}
+	}
+}
+
+func TestConcurrencyWaitTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := blocking(started, release, httpx.WithConcurrencyLimit(1), httpx.WithTimeout(10*time.Millisecond))
+
+	first := make(chan struct{})
+	go func() {
+		serve(handler, http.MethodGet, "/")
+		close(first)
+	}()
+	<-started
+
+	w := serve(handler, http.MethodGet, "/")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("waiting request status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got, want := w.Body.String(), `{"message":"Service Unavailable"}`+"\n"; got != want {
+		t.Errorf("waiting request body = %q, want %q", got, want)
+	}
+
+	close(release)
+	<-first
+}
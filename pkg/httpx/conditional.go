@@ -0,0 +1,98 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// An ETagger is implemented by responses having an entity tag. Handle sets ETag header for them and answers
+// matching If-None-Match requests with [http.StatusNotModified] without a body.
+// Tag may be returned with or without quotes, weak tags are prefixed with W/
+type ETagger interface {
+	ETag() string
+}
+
+// formatETag quotes entity tag if it is not quoted yet
+func formatETag(tag string) string {
+	weak := strings.HasPrefix(tag, "W/")
+	opaque := strings.TrimPrefix(tag, "W/")
+
+	if len(opaque) < 2 || opaque[0] != '"' || opaque[len(opaque)-1] != '"' {
+		opaque = `"` + opaque + `"`
+	}
+
+	if weak {
+		return "W/" + opaque
+	}
+	return opaque
+}
+
+// etagMatches reports whether If-None-Match header matches etag using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A LastModifier is implemented by responses having a modification time. Handle sets Last-Modified header for them
+// and answers GET and HEAD requests with If-Modified-Since not older than the time with [http.StatusNotModified].
+// Time is compared with second granularity, If-Modified-Since is ignored when If-None-Match is sent
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+// writeNotModified sets validator headers of the response and writes [http.StatusNotModified] for GET and HEAD
+// or [http.StatusPreconditionFailed] for other methods when request preconditions match. Returns true if
+// the response is already written
+func writeNotModified(w http.ResponseWriter, r *http.Request, response any) bool {
+	var (
+		etag     string
+		modified time.Time
+		safe     = r.Method == http.MethodGet || r.Method == http.MethodHead
+	)
+
+	if eTagger, ok := response.(ETagger); ok {
+		etag = formatETag(eTagger.ETag())
+		w.Header().Set("ETag", etag)
+	}
+
+	if lastModifier, ok := response.(LastModifier); ok {
+		modified = lastModifier.LastModified().UTC().Truncate(time.Second)
+		if !modified.IsZero() {
+			w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		}
+	}
+
+	ifNoneMatch := strings.Join(r.Header.Values("If-None-Match"), ",")
+	switch {
+	case ifNoneMatch != "":
+		if etag == "" || !etagMatches(ifNoneMatch, etag) {
+			return false
+		}
+		if !safe {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return true
+		}
+	case safe && !modified.IsZero():
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err != nil || modified.After(since) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,143 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type taggedItem struct {
+	Name string `json:"name"`
+	tag  string
+}
+
+func (i taggedItem) ETag() string {
+	return i.tag
+}
+
+// conditional serves request with header set to value
+func conditional(h http.Handler, method, header, value string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/", nil)
+	if header != "" {
+		r.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestETag(t *testing.T) {
+	tests := map[string]struct {
+		tag         string
+		method      string
+		ifNoneMatch string
+		wantStatus  int
+		wantETag    string
+	}{
+		"sets quoted tag":     {tag: "v1", method: http.MethodGet, wantStatus: http.StatusOK, wantETag: `"v1"`},
+		"keeps weak tag":      {tag: `W/"v1"`, method: http.MethodGet, wantStatus: http.StatusOK, wantETag: `W/"v1"`},
+		"matching tag":        {tag: "v1", method: http.MethodGet, ifNoneMatch: `"v1"`, wantStatus: http.StatusNotModified, wantETag: `"v1"`},
+		"weak comparison":     {tag: "v1", method: http.MethodGet, ifNoneMatch: `"v0", W/"v1"`, wantStatus: http.StatusNotModified, wantETag: `"v1"`},
+		"wildcard":            {tag: "v1", method: http.MethodGet, ifNoneMatch: "*", wantStatus: http.StatusNotModified, wantETag: `"v1"`},
+		"stale tag":           {tag: "v2", method: http.MethodGet, ifNoneMatch: `"v1"`, wantStatus: http.StatusOK, wantETag: `"v2"`},
+		"unsafe method match": {tag: "v1", method: http.MethodPut, ifNoneMatch: `"v1"`, wantStatus: http.StatusPreconditionFailed, wantETag: `"v1"`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := httpx.Handle[emptyRequest, taggedItem](func(context.Context, emptyRequest) (taggedItem, error) {
+				return taggedItem{Name: "item", tag: tt.tag}, nil
+			})
+
+			header := ""
+			if tt.ifNoneMatch != "" {
+				header = "If-None-Match"
+			}
+			w := conditional(handler, tt.method, header, tt.ifNoneMatch)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if tt.wantStatus == http.StatusNotModified && (w.Body.Len() > 0 || w.Header().Get("Content-Type") != "") {
+				t.Errorf("304 response has body %q and Content-Type %q", w.Body.String(), w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+type modifiedItem struct {
+	Name     string `json:"name"`
+	modified time.Time
+}
+
+func (i modifiedItem) LastModified() time.Time {
+	return i.modified
+}
+
+func (i modifiedItem) ETag() string {
+	return "v2"
+}
+
+func TestLastModified(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 10, 30, 0, 500_000_000, time.UTC)
+
+	tests := map[string]struct {
+		method          string
+		ifModifiedSince string
+		wantStatus      int
+	}{
+		"no condition":      {method: http.MethodGet, wantStatus: http.StatusOK},
+		"same second":       {method: http.MethodGet, ifModifiedSince: "Wed, 01 May 2024 10:30:00 GMT", wantStatus: http.StatusNotModified},
+		"later":             {method: http.MethodHead, ifModifiedSince: "Thu, 02 May 2024 00:00:00 GMT", wantStatus: http.StatusNotModified},
+		"newer resource":    {method: http.MethodGet, ifModifiedSince: "Wed, 01 May 2024 10:29:59 GMT", wantStatus: http.StatusOK},
+		"malformed":         {method: http.MethodGet, ifModifiedSince: "yesterday", wantStatus: http.StatusOK},
+		"ignored on unsafe": {method: http.MethodPut, ifModifiedSince: "Thu, 02 May 2024 00:00:00 GMT", wantStatus: http.StatusOK},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := httpx.Handle[emptyRequest, modifiedItem](func(context.Context, emptyRequest) (modifiedItem, error) {
+				return modifiedItem{Name: "item", modified: modified}, nil
+			})
+
+			header := ""
+			if tt.ifModifiedSince != "" {
+				header = "If-Modified-Since"
+			}
+			w := conditional(handler, tt.method, header, tt.ifModifiedSince)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Last-Modified"); got != "Wed, 01 May 2024 10:30:00 GMT" {
+				t.Errorf("Last-Modified = %q", got)
+			}
+			if tt.wantStatus == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("body = %q, want empty", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLastModifiedIgnoredWithIfNoneMatch(t *testing.T) {
+	handler := httpx.Handle[emptyRequest, modifiedItem](func(context.Context, emptyRequest) (modifiedItem, error) {
+		return modifiedItem{Name: "item", modified: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}, nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", `"v1"`)
+	r.Header.Set("If-Modified-Since", "Thu, 02 May 2024 00:00:00 GMT")
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
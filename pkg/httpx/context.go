@@ -0,0 +1,58 @@
+package httpx
+
+import "context"
+
+// A ContextKey is a typed key of context values. Every key created with NewContextKey is unique, so values
+// of different packages never collide as it happens with string keys, and Value returns T without type assertions.
+//
+// Define keys as package level variables next to the middleware which populates them:
+//
+//	var UserKey = httpx.NewContextKey[User]("user")
+//
+//	httpx.BearerAuth(func(ctx context.Context, token string) (context.Context, error) {
+//		user, err := auth.Verify(ctx, token)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return UserKey.WithValue(ctx, user), nil
+//	})
+//
+//	func (u *useCase) Profile(ctx context.Context, req Request) (Profile, error) {
+//		user, ok := UserKey.Value(ctx)
+//		...
+//	}
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey creates unique key of T values, name is used only for debugging
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// WithValue returns copy of ctx carrying value under the key
+func (k *ContextKey[T]) WithValue(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, k, value)
+}
+
+// Value returns value stored under the key in ctx and reports whether it is present
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	value, ok := ctx.Value(k).(T)
+	return value, ok
+}
+
+func (k *ContextKey[T]) String() string {
+	return "httpx context key " + k.name
+}
+
+var tenantKey = NewContextKey[string]("tenant")
+
+// WithTenant returns copy of ctx carrying tenant id
+func WithTenant(ctx context.Context, id string) context.Context {
+	return tenantKey.WithValue(ctx, id)
+}
+
+// TenantFromContext returns tenant id stored with WithTenant and reports whether it is present
+func TenantFromContext(ctx context.Context) (string, bool) {
+	return tenantKey.Value(ctx)
+}
@@ -0,0 +1,62 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// tenantBoundRequest keeps tenant seen by Bind
+type tenantBoundRequest struct {
+	httpx.DefaultRequest
+	tenant string
+}
+
+func (r *tenantBoundRequest) Bind(req *http.Request) error {
+	r.tenant, _ = httpx.TenantFromContext(req.Context())
+	return nil
+}
+
+func (r tenantBoundRequest) String() string {
+	return r.tenant
+}
+
+var stepsKey = httpx.NewContextKey[[]string]("steps")
+
+func TestWithContextFunc(t *testing.T) {
+	var (
+		boundTenant string
+		tenant      string
+		steps       []string
+	)
+
+	handler := httpx.Handle(func(ctx context.Context, req tenantBoundRequest) (string, error) {
+		boundTenant = req.tenant
+		tenant, _ = httpx.TenantFromContext(ctx)
+		steps, _ = stepsKey.Value(ctx)
+		return "ok", nil
+	}, httpx.WithContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+		return httpx.WithTenant(ctx, r.Header.Get("X-Tenant-ID"))
+	}), httpx.WithContextFunc(func(ctx context.Context, _ *http.Request) context.Context {
+		tenant, _ := httpx.TenantFromContext(ctx)
+		return stepsKey.WithValue(ctx, []string{"tenant " + tenant, "second"})
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Tenant-ID", "acme")
+	handler(httptest.NewRecorder(), r)
+
+	if boundTenant != "acme" {
+		t.Errorf("tenant in Bind = %q, want %q", boundTenant, "acme")
+	}
+	if tenant != "acme" {
+		t.Errorf("tenant in use case = %q, want %q", tenant, "acme")
+	}
+	if want := []string{"tenant acme", "second"}; !slices.Equal(steps, want) {
+		t.Errorf("steps = %q, want %q, functions must run in registration order", steps, want)
+	}
+}
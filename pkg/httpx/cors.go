@@ -0,0 +1,119 @@
+package httpx
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures CORS middleware
+type CORSOptions struct {
+	// AllowedOrigins is a list of origins allowed to make cross-origin requests, "*" allows any origin
+	AllowedOrigins []string
+	// AllowOriginFunc is called for origins which are not listed in AllowedOrigins
+	AllowOriginFunc func(origin string) bool
+	// AllowedMethods is a list of methods allowed in cross-origin requests. Default value is GET, HEAD and POST
+	AllowedMethods []string
+	// AllowedHeaders is a list of request headers allowed in cross-origin requests, "*" allows any header
+	AllowedHeaders []string
+	// ExposedHeaders is a list of response headers exposed to the client
+	ExposedHeaders []string
+	// AllowCredentials allows requests with cookies and authorization headers
+	AllowCredentials bool
+	// MaxAge is a duration for which preflight response can be cached by the client
+	MaxAge time.Duration
+}
+
+// CORS returns middleware handling Cross-Origin Resource Sharing. Preflight OPTIONS requests are answered with
+// [http.StatusNoContent] without calling the wrapped handler, actual requests get CORS headers and are passed through.
+// Preflight requests do not match method patterns like "GET /users", so apply it to a Router group, which answers
+// OPTIONS through its middlewares, or wrap the whole mux. A use case wrapped with WithMiddleware never sees preflight
+//
+// Usage:
+//
+//	cors := httpx.CORS(httpx.CORSOptions{
+//		AllowedOrigins: []string{"https://example.com"},
+//		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+//	})
+//
+//	api := router.Group("/api", cors)
+//	httpx.Get(api, "/users", userUseCase.List)
+//
+//	// or for plain mux
+//	http.ListenAndServe(":8080", cors(mux))
+func CORS(opts CORSOptions) Middleware {
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+
+	var (
+		anyOrigin = slices.Contains(opts.AllowedOrigins, "*")
+		anyHeader = slices.Contains(opts.AllowedHeaders, "*")
+		methods   = strings.Join(opts.AllowedMethods, ", ")
+		headers   = strings.Join(opts.AllowedHeaders, ", ")
+		exposed   = strings.Join(opts.ExposedHeaders, ", ")
+	)
+
+	allowed := func(origin string) bool {
+		if anyOrigin || slices.Contains(opts.AllowedOrigins, origin) {
+			return true
+		}
+		return opts.AllowOriginFunc != nil && opts.AllowOriginFunc(origin)
+	}
+
+	setOrigin := func(header http.Header, origin string) {
+		if anyOrigin && !opts.AllowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opts.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				header = w.Header()
+				origin = r.Header.Get("Origin")
+			)
+
+			header.Add("Vary", "Origin")
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+
+				if origin != "" && allowed(origin) {
+					setOrigin(header, origin)
+					header.Set("Access-Control-Allow-Methods", methods)
+					if anyHeader {
+						if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+							header.Set("Access-Control-Allow-Headers", requested)
+						}
+					} else if headers != "" {
+						header.Set("Access-Control-Allow-Headers", headers)
+					}
+					if opts.MaxAge > 0 {
+						header.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+					}
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if origin != "" && allowed(origin) {
+				setOrigin(header, origin)
+				if exposed != "" {
+					header.Set("Access-Control-Expose-Headers", exposed)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
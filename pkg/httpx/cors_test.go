@@ -0,0 +1,80 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestCORSPreflightOnRouterGroup(t *testing.T) {
+	var (
+		router = httpx.NewRouter()
+		api    = router.Group("/api", httpx.CORS(httpx.CORSOptions{
+			AllowedOrigins: []string{"https://example.com"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			AllowedHeaders: []string{"Content-Type"},
+		}))
+	)
+	httpx.Get(api, "/users", reply("users"))
+
+	r := httptest.NewRequest(http.MethodOptions, "/api/users", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://example.com",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "Content-Type",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	handler := httpx.CORS(httpx.CORSOptions{
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{"X-Request-ID"},
+	})(httpx.HandleNoReq(func(ctx context.Context) (string, error) {
+		return "ok", nil
+	}))
+
+	for origin, want := range map[string]string{"https://any.example": "*", "": ""} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("origin %q: status = %d", origin, w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("origin %q: Access-Control-Allow-Origin = %q, want %q", origin, got, want)
+		}
+	}
+}
+
+func TestCORSRejectedOrigin(t *testing.T) {
+	handler := httpx.CORS(httpx.CORSOptions{AllowedOrigins: []string{"https://example.com"}})(http.NotFoundHandler())
+
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("Origin", "https://evil.example")
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+	}
+}
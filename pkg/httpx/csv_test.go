@@ -0,0 +1,42 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type reportRow struct {
+	ID   int    `csv:"id"`
+	Name string `csv:"name"`
+}
+
+type report []reportRow
+
+func (report) Header() http.Header {
+	return http.Header{"content-disposition": {`attachment; filename="report.csv"`}}
+}
+
+func TestCsvReport(t *testing.T) {
+	handler := httpx.Handle[emptyRequest, report](func(context.Context, emptyRequest) (report, error) {
+		return report{{ID: 1, Name: "bob"}}, nil
+	}, httpx.WithEncoder(encoder.CsvEncoder))
+
+	w := serve(handler, http.MethodGet, "/report")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want %q", got, "text/csv; charset=utf-8")
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="report.csv"` {
+		t.Errorf("Content-Disposition = %q, want attachment", got)
+	}
+	if want := "id,name\n1,bob\n"; w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+}
@@ -0,0 +1,48 @@
+package httpx
+
+// Defaults is a reusable set of options to configure encoder, logger and other options of all handlers once.
+// Go methods can not have type parameters, so merged options are passed to Handle and its variants.
+// Options are applied in order, so per-call options override the default ones
+//
+// Usage:
+//
+//	defaults := httpx.NewDefaults(httpx.WithLogger(logger), httpx.WithEncoder(encoder.JsonEncoder))
+//	mux.HandleFunc("GET /users", httpx.Handle[ListRequest, ListResponse](userUseCase.List, defaults.Options()...))
+//	mux.HandleFunc("POST /users", httpx.Handle[CreateRequest, User](userUseCase.Create, defaults.Options(httpx.WithSuccessCode(http.StatusCreated))...))
+type Defaults struct {
+	options []Option
+}
+
+// NewDefaults creates Defaults holding options
+func NewDefaults(options ...Option) Defaults {
+	return Defaults{options: append([]Option(nil), options...)}
+}
+
+// Options returns default options followed by overrides
+func (d Defaults) Options(overrides ...Option) []Option {
+	return append(append(make([]Option, 0, len(d.options)+len(overrides)), d.options...), overrides...)
+}
+
+// With returns new Defaults extended with options, d is not modified
+func (d Defaults) With(options ...Option) Defaults {
+	return Defaults{options: d.Options(options...)}
+}
+
+// globalOptions are set by SetDefaults and applied before options of every handler
+var globalOptions []Option
+
+// SetDefaults sets process-wide options applied to every handler created afterwards, so existing Handle calls
+// pick up custom logger, encoder and other options without passing them around. Options are resolved with precedence
+// per-call options > global defaults > built-in defaults. Each call replaces previously set defaults.
+//
+// SetDefaults is not safe for concurrent use, call it during initialization before creating handlers and serving.
+// Handlers created before the call keep options they were created with
+//
+// Usage:
+//
+//	func init() {
+//		httpx.SetDefaults(httpx.WithLogger(logger), httpx.WithEncoder(encoder.JsonEncoder))
+//	}
+func SetDefaults(options ...Option) {
+	globalOptions = append([]Option(nil), options...)
+}
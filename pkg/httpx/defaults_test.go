@@ -0,0 +1,72 @@
+package httpx_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestDefaults(t *testing.T) {
+	var (
+		defaults = httpx.NewDefaults(httpx.WithSuccessCode(http.StatusAccepted))
+		extended = defaults.With(httpx.WithSuccessCode(http.StatusCreated))
+	)
+
+	tests := map[string]struct {
+		options []httpx.Option
+		want    int
+	}{
+		"defaults":         {options: defaults.Options(), want: http.StatusAccepted},
+		"override":         {options: defaults.Options(httpx.WithSuccessCode(http.StatusOK)), want: http.StatusOK},
+		"extended":         {options: extended.Options(), want: http.StatusCreated},
+		"source unchanged": {options: defaults.Options(), want: http.StatusAccepted},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if w := serve(httpx.Handle[emptyRequest, string](reply("ok"), tt.options...), http.MethodGet, "/"); w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultsOptionsDoNotAlias(t *testing.T) {
+	defaults := httpx.NewDefaults(httpx.WithSuccessCode(http.StatusAccepted))
+
+	a := defaults.Options(httpx.WithSuccessCode(http.StatusCreated))
+	b := defaults.Options(httpx.WithSuccessCode(http.StatusOK))
+
+	if w := serve(httpx.Handle[emptyRequest, string](reply("ok"), a...), http.MethodGet, "/"); w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if w := serve(httpx.Handle[emptyRequest, string](reply("ok"), b...), http.MethodGet, "/"); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestWithSuccessCodeValidation(t *testing.T) {
+	for _, code := range []int{0, 99, 600, -1} {
+		t.Run(strconv.Itoa(code), func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithSuccessCode(%d) did not panic", code)
+				}
+			}()
+			httpx.WithSuccessCode(code)
+		})
+	}
+
+	for _, code := range []int{http.StatusContinue, http.StatusOK, http.StatusCreated, 599} {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("WithSuccessCode(%d) panicked: %v", code, r)
+				}
+			}()
+			httpx.WithSuccessCode(code)
+		}()
+	}
+}
@@ -0,0 +1,226 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func fail(err error) httpx.UseCaseFunc[emptyRequest, string] {
+	return func(context.Context, emptyRequest) (string, error) {
+		return "", err
+	}
+}
+
+func TestErrorCodeInResponse(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		body string
+	}{
+		"with code": {
+			err:  errorsx.New(false, http.StatusNotFound, "user not found").WithErrorCode("USER_NOT_FOUND"),
+			body: `{"code":"USER_NOT_FOUND","status":404,"message":"user not found"}` + "\n",
+		},
+		"without code": {
+			err:  errorsx.New(false, http.StatusNotFound, "user not found"),
+			body: `"user not found"` + "\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serve(httpx.Handle(fail(tc.err)), http.MethodGet, "/")
+			if w.Code != http.StatusNotFound || w.Body.String() != tc.body {
+				t.Errorf("response = %d %q, want 404 %q", w.Code, w.Body.String(), tc.body)
+			}
+		})
+	}
+}
+
+func TestErrorBodyFallsBackToJSON(t *testing.T) {
+	handler := httpx.Handle(fail(errorsx.New(false, http.StatusNotFound, "report not found")),
+		httpx.WithEncoder(encoder.CsvEncoder))
+
+	w := serve(handler, http.MethodGet, "/")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+	if got := w.Body.String(); got != `"report not found"`+"\n" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestJoinedErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		code int
+	}{
+		"not found and bad request": {
+			err:  errors.Join(errorsx.New(false, http.StatusBadRequest, "bad"), errorsx.New(false, http.StatusNotFound, "missing")),
+			code: http.StatusNotFound,
+		},
+		"internal and bad request": {
+			err:  errors.Join(errorsx.New(false, http.StatusBadRequest, "bad"), errorsx.New(true, http.StatusInternalServerError, "db")),
+			code: http.StatusInternalServerError,
+		},
+		"plain and bad request": {
+			err:  errors.Join(errorsx.New(false, http.StatusBadRequest, "bad"), errors.New("db")),
+			code: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if w := serve(httpx.Handle(fail(tc.err)), http.MethodGet, "/"); w.Code != tc.code {
+				t.Errorf("status = %d, want %d", w.Code, tc.code)
+			}
+		})
+	}
+}
+
+func TestInternalErrorStack(t *testing.T) {
+	var logs bytes.Buffer
+	handler := httpx.Handle(fail(errorsx.New(true, http.StatusInternalServerError, "db is down").WithStack()), bufferLogger(&logs))
+
+	w := serve(handler, http.MethodGet, "/")
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(logs.String(), `"stack":"`) || !strings.Contains(logs.String(), "TestInternalErrorStack") {
+		t.Errorf("logs = %s, want stack of the error", logs.String())
+	}
+	if body := w.Body.String(); strings.Contains(body, "TestInternalErrorStack") || strings.Contains(body, "db is down") {
+		t.Errorf("body = %q, want no stack or internal message", body)
+	}
+}
+
+// badBindRequest fails to bind with plain error
+type badBindRequest struct {
+	httpx.DefaultRequest
+}
+
+func (*badBindRequest) Bind(*http.Request) error {
+	return errors.New("malformed query")
+}
+
+func (badBindRequest) String() string {
+	return "bad bind"
+}
+
+// badValidateRequest fails validation with plain error
+type badValidateRequest struct {
+	httpx.DefaultRequest
+}
+
+func (*badValidateRequest) Validate() error {
+	return errors.New("name is required")
+}
+
+func (badValidateRequest) String() string {
+	return "bad validate"
+}
+
+func TestDefaultBindAndValidateCodes(t *testing.T) {
+	bindFails := func(options ...httpx.Option) http.Handler {
+		return httpx.Handle(func(context.Context, badBindRequest) (string, error) {
+			return "", nil
+		}, options...)
+	}
+	validateFails := func(options ...httpx.Option) http.Handler {
+		return httpx.Handle(func(context.Context, badValidateRequest) (string, error) {
+			return "", nil
+		}, options...)
+	}
+
+	for name, tc := range map[string]struct {
+		handler http.Handler
+		code    int
+		message string
+	}{
+		"bind":              {handler: bindFails(), code: http.StatusBadRequest, message: "malformed query"},
+		"bind override":     {handler: bindFails(httpx.WithDefaultBindCode(http.StatusNotAcceptable)), code: http.StatusNotAcceptable, message: "malformed query"},
+		"validate":          {handler: validateFails(), code: http.StatusUnprocessableEntity, message: "name is required"},
+		"validate override": {handler: validateFails(httpx.WithDefaultValidateCode(http.StatusBadRequest)), code: http.StatusBadRequest, message: "name is required"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serve(tc.handler, http.MethodGet, "/")
+
+			if w.Code != tc.code {
+				t.Errorf("status = %d, want %d", w.Code, tc.code)
+			}
+			if !strings.Contains(w.Body.String(), tc.message) {
+				t.Errorf("body = %q, want message %q", w.Body.String(), tc.message)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		want string
+	}{
+		"seconds":        {err: errorsx.New(false, http.StatusServiceUnavailable, "maintenance").WithRetryAfter(30 * time.Second), want: "30"},
+		"rounded up":     {err: errorsx.New(false, http.StatusTooManyRequests, "slow down").WithRetryAfter(1500 * time.Millisecond), want: "2"},
+		"unset":          {err: errorsx.New(false, http.StatusServiceUnavailable, "maintenance")},
+		"internal error": {err: errorsx.New(true, http.StatusServiceUnavailable, "db is down").WithRetryAfter(time.Minute)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serve(httpx.Handle(fail(tc.err)), http.MethodGet, "/")
+
+			if got := w.Header().Get("Retry-After"); got != tc.want {
+				t.Errorf("Retry-After = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInternalErrorBody(t *testing.T) {
+	type problem struct {
+		Error   string `json:"error"`
+		TraceID string `json:"trace_id"`
+	}
+
+	for name, tc := range map[string]struct {
+		options []httpx.Option
+		want    string
+	}{
+		"default": {
+			want: `{"message":"Internal Server Error","request_id":"req-1"}` + "\n",
+		},
+		"custom": {
+			options: []httpx.Option{httpx.WithInternalErrorBody(func(id string) any {
+				return problem{Error: "something went wrong", TraceID: id}
+			})},
+			want: `{"error":"something went wrong","trace_id":"req-1"}` + "\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := httpx.Handle(fail(errors.New("pq: password authentication failed")), tc.options...)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(httpx.RequestIDHeader, "req-1")
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := w.Body.String(); got != tc.want {
+				t.Errorf("body = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
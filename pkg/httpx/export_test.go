@@ -0,0 +1,17 @@
+package httpx
+
+import "time"
+
+// WithClock sets function reporting current time to handler, so tests control measured durations
+func WithClock(now func() time.Time) Option {
+	return func(h *handlerOptions) {
+		h.now = now
+	}
+}
+
+// WithRandom sets source of numbers from 0 to 1 sampling error logs, so tests know which errors are logged
+func WithRandom(random func() float64) Option {
+	return func(h *handlerOptions) {
+		h.random = random
+	}
+}
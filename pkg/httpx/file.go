@@ -0,0 +1,78 @@
+package httpx
+
+import (
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// A FileResponse is a streamed download response, Reader is copied to the client bypassing configured encoder
+// and closed when it implements [io.Closer]. Empty ContentType is sent as application/octet-stream,
+// Content-Length is set only when Size is positive. Only metadata of the file is logged
+//
+// Usage:
+//
+//	func (u *useCase) Download(ctx context.Context, req Request) (httpx.FileResponse, error) {
+//		f, err := os.Open(req.Path)
+//		if err != nil {
+//			return httpx.FileResponse{}, err
+//		}
+//		return httpx.FileResponse{Reader: f, ContentType: "text/csv", Filename: "report.csv"}, nil
+//	}
+type FileResponse struct {
+	Reader      io.Reader
+	ContentType string
+	Filename    string
+	Size        int64
+}
+
+func (f FileResponse) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("content_type", f.ContentType),
+		slog.String("filename", f.Filename),
+		slog.Int64("size", f.Size),
+	)
+}
+
+// writeFile streams file response with code, reader is closed afterwards
+func (h *handlerOptions) writeFile(w http.ResponseWriter, r *http.Request, logger *slog.Logger, code int, file FileResponse) {
+	if closer, ok := file.Reader.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				logger.ErrorContext(r.Context(), "failed to close file", slog.Any("err", err))
+			}
+		}()
+	}
+
+	logger.LogAttrs(r.Context(), slog.LevelInfo, "response", slog.Any("response", file))
+
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	disposition := "attachment"
+	if file.Filename != "" {
+		disposition = mime.FormatMediaType(disposition, map[string]string{"filename": file.Filename})
+	}
+	w.Header().Set("Content-Disposition", disposition)
+
+	if file.Size > 0 && (!h.gzip || !acceptsGzip(r)) {
+		w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	}
+	w.WriteHeader(code)
+	if file.Reader == nil || r.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := io.Copy(w, file.Reader); err != nil {
+		if connectionClosed(err) {
+			logger.DebugContext(r.Context(), "client closed connection", slog.Any("err", err))
+			return
+		}
+		logger.ErrorContext(r.Context(), "failed to write file", slog.Any("err", err))
+	}
+}
@@ -0,0 +1,93 @@
+package httpx_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// closeTracker records whether the file was closed
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestFileResponse(t *testing.T) {
+	for name, tc := range map[string]struct {
+		method      string
+		file        httpx.FileResponse
+		contentType string
+		disposition string
+		length      string
+		body        string
+	}{
+		"with size": {
+			method:      http.MethodGet,
+			file:        httpx.FileResponse{ContentType: "text/csv", Filename: "report.csv", Size: 8},
+			contentType: "text/csv",
+			disposition: "attachment; filename=report.csv",
+			length:      "8",
+			body:        "id,name\n",
+		},
+		"unknown size": {
+			method:      http.MethodGet,
+			file:        httpx.FileResponse{Filename: "report.csv"},
+			contentType: "application/octet-stream",
+			disposition: "attachment; filename=report.csv",
+			body:        "id,name\n",
+		},
+		"non-ascii filename": {
+			method:      http.MethodGet,
+			file:        httpx.FileResponse{ContentType: "text/csv", Filename: "отчёт.csv"},
+			contentType: "text/csv",
+			disposition: "attachment; filename*=utf-8''%D0%BE%D1%82%D1%87%D1%91%D1%82.csv",
+			body:        "id,name\n",
+		},
+		"head": {
+			method:      http.MethodHead,
+			file:        httpx.FileResponse{ContentType: "text/csv", Size: 8},
+			contentType: "text/csv",
+			disposition: "attachment",
+			length:      "8",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reader := &closeTracker{Reader: strings.NewReader("id,name\n")}
+			file := tc.file
+			file.Reader = reader
+			handler := httpx.Handle(func(context.Context, emptyRequest) (httpx.FileResponse, error) {
+				return file, nil
+			})
+
+			w := serve(handler, tc.method, "/report")
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			for header, want := range map[string]string{
+				"Content-Type":        tc.contentType,
+				"Content-Disposition": tc.disposition,
+				"Content-Length":      tc.length,
+			} {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+			if got := w.Body.String(); got != tc.body {
+				t.Errorf("body = %q, want %q", got, tc.body)
+			}
+			if !reader.closed {
+				t.Error("reader is not closed")
+			}
+		})
+	}
+}
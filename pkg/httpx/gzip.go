@@ -0,0 +1,168 @@
+package httpx
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipPools holds pools of writers for every compression level from gzip.HuffmanOnly to gzip.BestCompression
+var gzipPools [gzip.BestCompression - gzip.HuffmanOnly + 1]sync.Pool
+
+func init() {
+	for i := range gzipPools {
+		level := i + gzip.HuffmanOnly
+		gzipPools[i].New = func() any {
+			gz, _ := gzip.NewWriterLevel(io.Discard, level)
+			return gz
+		}
+	}
+}
+
+func validGzipLevel(level int) bool {
+	return level >= gzip.HuffmanOnly && level <= gzip.BestCompression
+}
+
+// acceptsGzip reports whether client advertised gzip in Accept-Encoding header
+func acceptsGzip(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(part, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "gzip" && coding != "*" {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// gzipResponseWriter compresses response body lazily on the first non-empty write,
+// so empty bodies and status codes without a body are sent as is
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	pool        *sync.Pool
+	gz          *gzip.Writer
+	code        int
+	wroteHeader bool
+	passthrough bool
+}
+
+func newGzipResponseWriter(w http.ResponseWriter, level int) *gzipResponseWriter {
+	return &gzipResponseWriter{
+		ResponseWriter: w,
+		pool:           &gzipPools[level-gzip.HuffmanOnly],
+		code:           http.StatusOK,
+	}
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	if g.wroteHeader || g.passthrough {
+		return
+	}
+
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified ||
+		g.Header().Get("Content-Encoding") != "" {
+		g.passthrough = true
+		g.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	g.code = code
+	g.wroteHeader = true
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if g.passthrough {
+		return g.ResponseWriter.Write(b)
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	g.start()
+	if g.passthrough {
+		return g.ResponseWriter.Write(b)
+	}
+
+	return g.gz.Write(b)
+}
+
+func (g *gzipResponseWriter) start() {
+	if g.gz != nil {
+		return
+	}
+
+	if g.Header().Get("Content-Encoding") != "" {
+		g.passthrough = true
+		g.ResponseWriter.WriteHeader(g.code)
+		return
+	}
+
+	g.Header().Set("Content-Encoding", "gzip")
+	g.Header().Del("Content-Length")
+	g.ResponseWriter.WriteHeader(g.code)
+
+	g.gz = g.pool.Get().(*gzip.Writer)
+	g.gz.Reset(g.ResponseWriter)
+}
+
+func (g *gzipResponseWriter) Flush() {
+	if !g.passthrough {
+		g.start()
+	}
+	if g.gz != nil {
+		_ = g.gz.Flush()
+	}
+	if flusher, ok := g.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// Close flushes gzip trailer or sends pending status code if nothing was written
+func (g *gzipResponseWriter) Close() error {
+	if g.gz != nil {
+		err := g.gz.Close()
+		g.gz.Reset(io.Discard)
+		g.pool.Put(g.gz)
+		g.gz = nil
+		return err
+	}
+
+	if g.wroteHeader && !g.passthrough {
+		g.passthrough = true
+		g.ResponseWriter.WriteHeader(g.code)
+	}
+
+	return nil
+}
+
+// gzipHandler compresses responses of next handler for clients accepting gzip
+func gzipHandler(level int, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next(w, r)
+			return
+		}
+
+		gw := newGzipResponseWriter(w, level)
+		defer gw.Close()
+
+		next(gw, r)
+	}
+}
@@ -0,0 +1,50 @@
+// Package httptestx provides you with helpers to test handlers created with httpx
+package httptestx
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+// Invoke encodes body as JSON, serves request to h with [httptest.ResponseRecorder] and decodes JSON response into Resp.
+// Returns decoded response and status code. When status code is 400 or above, response body is returned as error
+//
+// Usage:
+//
+//	resp, code, err := httptestx.Invoke[Request, Response](httpx.Handle[Request, Response](useCase), http.MethodPost, "/users", Request{Name: "john"})
+func Invoke[Req any, Resp any](h http.HandlerFunc, method, path string, body Req) (Resp, int, error) {
+	var (
+		resp Resp
+		buf  bytes.Buffer
+	)
+
+	if err := encoder.JsonEncoder.New(&buf).Encode(body); err != nil {
+		return resp, 0, fmt.Errorf("httptestx: encode request: %w", err)
+	}
+
+	r := httptest.NewRequest(method, path, &buf)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	h(w, r)
+
+	if w.Code >= http.StatusBadRequest {
+		return resp, w.Code, fmt.Errorf("httptestx: status %d: %s", w.Code, strings.TrimSpace(w.Body.String()))
+	}
+
+	if w.Body.Len() == 0 {
+		return resp, w.Code, nil
+	}
+
+	if err := encoder.JsonDecoder.New(w.Body).Decode(&resp); err != nil {
+		return resp, w.Code, fmt.Errorf("httptestx: decode response: %w", err)
+	}
+
+	return resp, w.Code, nil
+}
@@ -0,0 +1,111 @@
+package httptestx_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+	"github.com/abdivasiyev/rester/pkg/httpx/httptestx"
+)
+
+func TestMain(m *testing.M) {
+	httpx.SetDefaults(httpx.WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))))
+	os.Exit(m.Run())
+}
+
+type createRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *createRequest) Bind(req *http.Request) error {
+	return httpx.BindJSON(req, r)
+}
+
+func (r *createRequest) Validate() error {
+	if r.Name == "" {
+		return errorsx.New(false, http.StatusUnprocessableEntity, "name is required")
+	}
+	return nil
+}
+
+func (r createRequest) String() string {
+	return r.Name
+}
+
+type createResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func create(_ context.Context, req createRequest) (createResponse, error) {
+	if req.Name == "taken" {
+		return createResponse{}, errorsx.New(false, http.StatusConflict, "name is taken")
+	}
+	return createResponse{ID: 1, Name: req.Name}, nil
+}
+
+func TestInvoke(t *testing.T) {
+	handler := httpx.Handle(create, httpx.WithSuccessCode(http.StatusCreated))
+
+	resp, code, err := httptestx.Invoke[createRequest, createResponse](handler, http.MethodPost, "/users", createRequest{Name: "john"})
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if code != http.StatusCreated {
+		t.Errorf("code = %d, want %d", code, http.StatusCreated)
+	}
+	if want := (createResponse{ID: 1, Name: "john"}); resp != want {
+		t.Errorf("response = %+v, want %+v", resp, want)
+	}
+}
+
+func TestInvokeError(t *testing.T) {
+	handler := httpx.Handle(create)
+
+	tests := map[string]struct {
+		name string
+		code int
+		body string
+	}{
+		"use case error":   {name: "taken", code: http.StatusConflict, body: `"name is taken"`},
+		"validation error": {code: http.StatusUnprocessableEntity, body: `"name is required"`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, code, err := httptestx.Invoke[createRequest, createResponse](handler, http.MethodPost, "/users", createRequest{Name: tt.name})
+			if err == nil {
+				t.Fatal("Invoke() error = nil, want error with response body")
+			}
+			if code != tt.code {
+				t.Errorf("code = %d, want %d", code, tt.code)
+			}
+			if !strings.Contains(err.Error(), tt.body) {
+				t.Errorf("error = %q, want it to contain body %s", err, tt.body)
+			}
+			if resp != (createResponse{}) {
+				t.Errorf("response = %+v, want zero value", resp)
+			}
+		})
+	}
+}
+
+func TestInvokeDecodeError(t *testing.T) {
+	handler := httpx.HandleNoReq(func(context.Context) (string, error) {
+		return "not an object", nil
+	})
+
+	_, code, err := httptestx.Invoke[createRequest, createResponse](handler, http.MethodGet, "/", createRequest{})
+	if code != http.StatusOK {
+		t.Errorf("code = %d, want %d", code, http.StatusOK)
+	}
+	if err == nil || !strings.Contains(err.Error(), "decode response") {
+		t.Errorf("error = %v, want decode error", err)
+	}
+}
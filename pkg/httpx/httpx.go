@@ -2,18 +2,43 @@
 package httpx
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net"
 	"net/http"
-
-	"github.com/google/uuid"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
 
 	"github.com/abdivasiyev/rester/pkg/encoder"
 	"github.com/abdivasiyev/rester/pkg/errorsx"
 	"github.com/abdivasiyev/rester/pkg/slogx"
 )
 
+// RequestIDHeader is a header to pass request id from clients and upstream proxies
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is a canonical form of RequestIDHeader, reading header with it avoids canonicalization per request
+var requestIDKey = http.CanonicalHeaderKey(RequestIDHeader)
+
+// StatusClientClosedRequest is a non-standard status recorded in logs, metrics and traces for requests
+// whose client disconnected before the response was written. Nothing is sent to the client
+const StatusClientClosedRequest = 499
+
 // A Validatable interface to implement validation function individually for every request type using Validate function
 type Validatable interface {
 	Validate() error
@@ -24,8 +49,22 @@ type Bindable interface {
 	Bind(*http.Request) error
 }
 
+// A StreamBindable interface is implemented by requests which read large bodies incrementally, e.g. newline-delimited JSON.
+// When request implements it, Handle calls BindStream with request body instead of Bind. Validation runs after
+// BindStream returns, so all the body is already consumed at that moment
+type StreamBindable interface {
+	BindStream(ctx context.Context, body io.Reader) error
+}
+
 // A Request is a type parameter to pass user provided request to Handle method
 // In this type parameter following constraints are added: Bindable, Validatable and [fmt.Stringer]
+//
+// Handle logs bound request as is, implement [slog.LogValuer] to control what is logged, e.g. to mask secrets.
+// The same applies to responses:
+//
+//	func (r LoginRequest) LogValue() slog.Value {
+//		return slog.GroupValue(slog.String("login", r.Login), slog.String("password", "***"))
+//	}
 type Request[Req any] interface {
 	Bindable
 	Validatable
@@ -46,32 +85,173 @@ func (*DefaultRequest) Validate() error {
 }
 
 type DefaultResponse struct {
-	Message string `json:"message"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// A StructValidator validates whole request structure, e.g. using struct tags.
+// Field errors should be returned as [errorsx.ValidationError]
+type StructValidator interface {
+	Validate(any) error
+}
+
+// A HeaderCarrier is implemented by responses which set additional headers to successful response.
+// For example CSV reports can be downloaded as a file:
+//
+//	type Report []Row
+//
+//	func (Report) Header() http.Header {
+//		return http.Header{"Content-Disposition": {`attachment; filename="report.csv"`}}
+//	}
+type HeaderCarrier interface {
+	Header() http.Header
 }
 
 // UseCaseFunc is a type to implement business logic functions
 type UseCaseFunc[Req any, Resp any] func(context.Context, Req) (Resp, error)
 
 type handlerOptions struct {
-	successCode int
-	encoder     encoder.Encoder
-	logger      *slog.Logger
+	successCode   int
+	encoderFunc   func(*http.Request) encoder.Encoder
+	encoderSet    bool
+	logger        *slog.Logger
+	requestLogger *slog.Logger
+	validator     StructValidator
+	maxBodySize   int64
+	gzip          bool
+	gzipLevel     int
+	registry      *encoder.Registry
+	middlewares   []Middleware
+	outer         []Middleware
+	idGenerator   func() string
+	idFunc        func(*http.Request) string
+	noRequestID   bool
+	contextFuncs  []func(context.Context, *http.Request) context.Context
+	contentTypes  []string
+	slowAfter     time.Duration
+	now           func() time.Time
+	timeout       time.Duration
+	semaphore     chan struct{}
+	overflow      ConcurrencyOverflow
+	errorEncoder  encoder.Encoder
+	formatParam   string
+	bindCode      int
+	validateCode  int
+	flushEvery    int
+	flushInterval time.Duration
+
+	streamErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	internalErrorBody  func(id string) any
+	responseWrapper    func(ctx context.Context, response any) (any, error)
+	preferHandling     bool
+	errorLogSampling   bool
+	errorLogRate       float64
+	random             func() float64
+	summary            string
+	description        string
+	tags               []string
+	bindObserver       func(d time.Duration, err error)
+	validateObserver   func(d time.Duration, err error)
+	logAfterValidate   bool
+	errorObservers     []func(r *http.Request, err error)
 }
 
 // An Option is a type to set optional parameters to handler
 type Option func(h *handlerOptions)
 
-// WithSuccessCode sets success code to handler. Default value is a [http.StatusOK]
+// WithSuccessCode sets success code to handler. Default value is a [http.StatusOK].
+// Panics when code is out of 100-599 range, so typos are found while wiring handlers
 func WithSuccessCode(code int) Option {
+	if code < 100 || code > 599 {
+		panic(fmt.Sprintf("httpx: invalid success code %d", code))
+	}
+
 	return func(h *handlerOptions) {
 		h.successCode = code
 	}
 }
 
-// WithEncoder sets custom encoder to handler. Default value is a [encoder.JsonEncoder]
-func WithEncoder(encoder encoder.Encoder) Option {
+// WithEncoder sets custom encoder to handler. Default value is a [encoder.JsonEncoder].
+// Encoders chosen by WithRegistry, WithFormatParam and responses implementing [encoder.ContentTyper]
+// take precedence, the encoder is used as a fallback for them
+func WithEncoder(e encoder.Encoder) Option {
+	return func(h *handlerOptions) {
+		h.encoderFunc = func(*http.Request) encoder.Encoder {
+			return e
+		}
+		h.encoderSet = false
+	}
+}
+
+// WithEncoderFunc sets function choosing encoder for every request, e.g. indented JSON for requests with debug header.
+// The encoder chosen by the function wins over all others: WithFormatParam, responses implementing
+// [encoder.ContentTyper] and negotiation by WithRegistry are not used. Nil encoder returned by the function
+// falls back to [encoder.JsonEncoder].
+//
+// Without it responses implementing [encoder.ContentTyper] are encoded with the encoder registered for their content type
+// in the registry set by WithRegistry or [encoder.DefaultRegistry], overriding WithFormatParam and negotiation
+func WithEncoderFunc(fn func(*http.Request) encoder.Encoder) Option {
+	return func(h *handlerOptions) {
+		h.encoderFunc = fn
+		h.encoderSet = true
+	}
+}
+
+// WithErrorEncoder sets encoder of error responses, e.g. producing application/problem+json.
+// Default value is the encoder of successful responses
+func WithErrorEncoder(encoder encoder.Encoder) Option {
 	return func(h *handlerOptions) {
-		h.encoder = encoder
+		h.errorEncoder = encoder
+	}
+}
+
+// WithInternalErrorBody sets function building body of [http.StatusInternalServerError] responses from request id.
+// Body is encoded with the error encoder, text of the underlying error is never passed to it.
+// Default value is a DefaultResponse with status text and request id
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithInternalErrorBody(func(id string) any {
+//		return ErrorResponse{Error: "something went wrong", TraceID: id}
+//	}))
+func WithInternalErrorBody(fn func(id string) any) Option {
+	return func(h *handlerOptions) {
+		h.internalErrorBody = fn
+	}
+}
+
+// WithResponseWrapper sets function transforming successful response before encoding, e.g. into a common envelope.
+// Result is unwrapped and headers, ETag and cache policy of the response are applied before fn is called,
+// Raw responses are not passed to it. Errors of fn are written as use case errors
+//
+// Usage:
+//
+//	httpx.WithResponseWrapper(func(ctx context.Context, response any) (any, error) {
+//		id, _ := httpx.RequestIDFromContext(ctx)
+//		return Envelope{Data: response, Meta: Meta{RequestID: id}}, nil
+//	})
+func WithResponseWrapper(fn func(ctx context.Context, response any) (any, error)) Option {
+	return func(h *handlerOptions) {
+		h.responseWrapper = fn
+	}
+}
+
+// WithPreferHandling lets clients ask for responses without a body with Prefer: return=minimal header (RFC 7240).
+// Status code and headers like Location and ETag are sent as usual, Preference-Applied header confirms
+// the preference. Default value is disabled
+func WithPreferHandling() Option {
+	return func(h *handlerOptions) {
+		h.preferHandling = true
+	}
+}
+
+// WithErrorLogSampling logs bind and validation errors which are not internal with probability rate from 0 to 1,
+// e.g. 0.01 logs every hundredth client error on average. Internal errors are always logged. Sampling affects
+// only logs, responses are written as usual. Default value is 1, every error is logged
+func WithErrorLogSampling(rate float64) Option {
+	return func(h *handlerOptions) {
+		h.errorLogSampling = true
+		h.errorLogRate = rate
 	}
 }
 
@@ -82,8 +262,277 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
+// WithValidator sets struct validator to handler. It runs after Validate method of the request returns nil,
+// errors which are not [errorsx.Errorx] are handled as described in WithDefaultValidateCode
+func WithValidator(validator StructValidator) Option {
+	return func(h *handlerOptions) {
+		h.validator = validator
+	}
+}
+
+// WithDefaultBindCode sets status code of errors returned by Bind which are not [errorsx.Errorx].
+// Default value is a [http.StatusBadRequest], as binding failures are caused by clients
+func WithDefaultBindCode(code int) Option {
+	return func(h *handlerOptions) {
+		h.bindCode = code
+	}
+}
+
+// WithDefaultValidateCode sets status code of errors returned by Validate method and struct validator
+// which are not [errorsx.Errorx]. Default value is a [http.StatusUnprocessableEntity], with default code
+// errors are returned as [errorsx.ValidationError]
+func WithDefaultValidateCode(code int) Option {
+	return func(h *handlerOptions) {
+		h.validateCode = code
+	}
+}
+
+// WithMaxBodySize limits request body to n bytes using [http.MaxBytesReader]. Requests exceeding the limit
+// are rejected with [http.StatusRequestEntityTooLarge]. Default value is unlimited, 1 << 20 (1 MB) is recommended
+// for JSON APIs.
+//
+// Requests declaring larger Content-Length are rejected before the body is read, so clients sending
+// Expect: 100-continue get the error instead of 100 Continue, which [http.Server] sends on the first read of the body.
+// Bodies without Content-Length are still limited by [http.MaxBytesReader] while being read
+func WithMaxBodySize(n int64) Option {
+	return func(h *handlerOptions) {
+		h.maxBodySize = n
+	}
+}
+
+// WithGzip compresses response body with given [gzip] level when client sends Accept-Encoding: gzip.
+// Empty responses are not compressed. Invalid level falls back to [gzip.DefaultCompression]
+func WithGzip(level int) Option {
+	return func(h *handlerOptions) {
+		h.gzip = true
+		h.gzipLevel = level
+	}
+}
+
+// WithRegistry enables content negotiation, encoder for a response is looked up in registry by Accept header
+// of the request. When nothing matches, the encoder set by WithEncoder is used. Pass [encoder.DefaultRegistry]
+// to use globally registered encoders
+func WithRegistry(registry *encoder.Registry) Option {
+	return func(h *handlerOptions) {
+		h.registry = registry
+	}
+}
+
+// WithFormatParam lets clients choose response encoder with query parameter name, e.g. ?format=xml, overriding
+// Accept header. Format is looked up in the registry set by WithRegistry or [encoder.DefaultRegistry] by content type
+// or its subtype. Requests with unknown format are rejected with [http.StatusBadRequest]
+func WithFormatParam(name string) Option {
+	return func(h *handlerOptions) {
+		h.formatParam = name
+	}
+}
+
+// WithStrictBody makes BindJSON and BindAll reject JSON bodies with keys which do not match any field of the request
+// with [http.StatusBadRequest] error naming the key, so misspelled keys are not silently ignored.
+// Path, query, header and form values are not affected. Default value is false, unknown keys are ignored
+func WithStrictBody() Option {
+	return WithContextFunc(func(ctx context.Context, _ *http.Request) context.Context {
+		return strictBodyKey.WithValue(ctx, true)
+	})
+}
+
+// WithKeyNormalizer lets BindQuery, BindForm and BindMultipart match keys which differ from the tag only
+// in the form normalized by fn, e.g. per_page, perPage and PerPage with NormalizeKey. Exact matches are preferred.
+// Default value is nil, keys must match tags exactly
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithKeyNormalizer(httpx.NormalizeKey))
+func WithKeyNormalizer(fn func(key string) string) Option {
+	return WithContextFunc(func(ctx context.Context, _ *http.Request) context.Context {
+		return keyNormalizerKey.WithValue(ctx, fn)
+	})
+}
+
+// WithMiddleware wraps handler with middlewares, the first middleware is the outermost
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(h *handlerOptions) {
+		h.middlewares = append(h.middlewares, middlewares...)
+	}
+}
+
+// WithOuterMiddleware wraps handler with middlewares running before request id is resolved and the request is logged,
+// e.g. tracing middleware providing trace id for WithRequestIDFunc. The first middleware is the outermost
+func WithOuterMiddleware(middlewares ...Middleware) Option {
+	return func(h *handlerOptions) {
+		h.outer = append(h.outer, middlewares...)
+	}
+}
+
+// WithErrorObserver adds function called with errors of binding, validation and use case before the error response
+// is written, e.g. to record them in spans. Multiple observers are called in order
+func WithErrorObserver(observer func(r *http.Request, err error)) Option {
+	return func(h *handlerOptions) {
+		h.errorObservers = append(h.errorObservers, observer)
+	}
+}
+
+// WithOptions combines options into one, e.g. to ship integrations configuring several options at once
+//
+// Usage:
+//
+//	var tracing = httpx.WithOptions(httpx.WithOuterMiddleware(startSpan), httpx.WithErrorObserver(recordError))
+func WithOptions(options ...Option) Option {
+	return func(h *handlerOptions) {
+		for _, option := range options {
+			option(h)
+		}
+	}
+}
+
+// WithIDGenerator sets generator of request ids used to group logs. Default value generates uuid.
+// Generator is not called when request carries X-Request-ID header
+func WithIDGenerator(generator func() string) Option {
+	return func(h *handlerOptions) {
+		h.idGenerator = generator
+	}
+}
+
+// WithRequestIDFunc sets function deriving request id from the request, e.g. trace id of the span started
+// by WithOuterMiddleware. Id from X-Request-ID header takes precedence, generator set by WithIDGenerator is used
+// when the function returns empty id
+func WithRequestIDFunc(fn func(r *http.Request) string) Option {
+	return func(h *handlerOptions) {
+		h.idFunc = fn
+	}
+}
+
+// WithoutRequestID disables request ids, logs of the request are written without grouping by id
+// and internal error responses carry no id. Use it for services with their own tracing to avoid the overhead
+func WithoutRequestID() Option {
+	return func(h *handlerOptions) {
+		h.noRequestID = true
+	}
+}
+
+// WithContextFunc enriches request context before binding, so Bind and use case see the returned context.
+// Multiple functions are applied in order
+//
+// Usage:
+//
+//	httpx.WithContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+//		return httpx.WithTenant(ctx, r.Header.Get("X-Tenant-ID"))
+//	})
+func WithContextFunc(fn func(context.Context, *http.Request) context.Context) Option {
+	return func(h *handlerOptions) {
+		h.contextFuncs = append(h.contextFuncs, fn)
+	}
+}
+
+// WithAcceptedContentTypes rejects requests with body of other content types with [http.StatusUnsupportedMediaType]
+// before Bind is called. Parameters like charset are ignored, requests without body are not checked
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithAcceptedContentTypes("application/json"))
+func WithAcceptedContentTypes(contentTypes ...string) Option {
+	return func(h *handlerOptions) {
+		for _, contentType := range contentTypes {
+			h.contentTypes = append(h.contentTypes, mediaType(contentType))
+		}
+	}
+}
+
+// WithSlowThreshold logs a warning with duration, method, path and status of requests handled longer than d,
+// in addition to the completion log. Zero value disables the warning
+func WithSlowThreshold(d time.Duration) Option {
+	return func(h *handlerOptions) {
+		h.slowAfter = d
+	}
+}
+
+// WithBindObserver sets fn called after every request binding with its duration and error returned by Bind,
+// e.g. to record binding latency separately from use case. Default value is nil
+func WithBindObserver(fn func(d time.Duration, err error)) Option {
+	return func(h *handlerOptions) {
+		h.bindObserver = fn
+	}
+}
+
+// WithValidateObserver sets fn called after every request validation with its duration and error returned by Validate
+// or validator set by WithValidator. It is not called when binding failed. Default value is nil
+func WithValidateObserver(fn func(d time.Duration, err error)) Option {
+	return func(h *handlerOptions) {
+		h.validateObserver = fn
+	}
+}
+
+// WithLogAfterValidate logs bound request only after it passed validation, so invalid requests are not logged
+// at info level as if they were accepted. Default value is false, request is logged before validation
+// with validated=false attribute
+func WithLogAfterValidate(after bool) Option {
+	return func(h *handlerOptions) {
+		h.logAfterValidate = after
+	}
+}
+
+// WithTimeout sets deadline of d to request context passed to Bind and use case. Requests waiting for
+// a concurrency slot longer than d and use cases failing with [context.DeadlineExceeded] after the deadline
+// are answered with [http.StatusServiceUnavailable]
+func WithTimeout(d time.Duration) Option {
+	return func(h *handlerOptions) {
+		h.timeout = d
+	}
+}
+
+// WithConcurrencyLimit limits number of requests handled concurrently by the handler to n.
+// Requests over the limit wait for a free slot by default, see WithConcurrencyOverflow
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithConcurrencyLimit(8), httpx.WithConcurrencyOverflow(httpx.ConcurrencyReject))
+func WithConcurrencyLimit(n int) Option {
+	return func(h *handlerOptions) {
+		h.semaphore = nil
+		if n > 0 {
+			h.semaphore = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithConcurrencyOverflow sets behavior for requests over the concurrency limit. Default value is a ConcurrencyWait
+func WithConcurrencyOverflow(overflow ConcurrencyOverflow) Option {
+	return func(h *handlerOptions) {
+		h.overflow = overflow
+	}
+}
+
+// WithFlushEvery flushes response of HandleStream after every n encoded items. Default value is 100,
+// non-positive value disables flushing by count. Option has no effect when response writer does not support flushing
+func WithFlushEvery(n int) Option {
+	return func(h *handlerOptions) {
+		h.flushEvery = n
+	}
+}
+
+// WithFlushInterval flushes response of HandleStream when d passed since the last flush, checked on every encoded item.
+// It can be combined with WithFlushEvery
+func WithFlushInterval(d time.Duration) Option {
+	return func(h *handlerOptions) {
+		h.flushInterval = d
+	}
+}
+
+// WithStreamErrorHandler sets function writing errors returned by use cases of HandleStream and HandleSSE
+// after the stream started. Status code is already sent at that moment, so handler can only write to the body.
+// Errors are logged before the handler is called
+func WithStreamErrorHandler(handler func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(h *handlerOptions) {
+		h.streamErrorHandler = handler
+	}
+}
+
 func applyOptions(options ...Option) handlerOptions {
-	var h handlerOptions
+	var h = handlerOptions{flushEvery: streamFlushEvery}
+
+	for _, option := range globalOptions {
+		option(&h)
+	}
 
 	for _, option := range options {
 		option(&h)
@@ -93,17 +542,381 @@ func applyOptions(options ...Option) handlerOptions {
 		h.successCode = http.StatusOK
 	}
 
-	if h.encoder == nil {
-		h.encoder = encoder.JsonEncoder
+	if h.bindCode <= 0 {
+		h.bindCode = http.StatusBadRequest
+	}
+
+	if h.validateCode <= 0 {
+		h.validateCode = http.StatusUnprocessableEntity
+	}
+
+	if h.encoderFunc == nil {
+		h.encoderFunc = func(*http.Request) encoder.Encoder {
+			return encoder.JsonEncoder
+		}
+	}
+
+	if h.gzip && !validGzipLevel(h.gzipLevel) {
+		h.gzipLevel = gzip.DefaultCompression
 	}
 
 	if h.logger == nil {
 		h.logger = slogx.New()
 	}
+	h.requestLogger = slog.New(&requestHandler{Handler: h.logger.Handler()})
+
+	if h.now == nil {
+		h.now = time.Now
+	}
+
+	if h.random == nil {
+		h.random = rand.Float64
+	}
 
 	return h
 }
 
+// requestInfo holds id, logger, the request itself, its handler and response recorder of logHandler. It is a context
+// carrying itself under requestInfoKey, so all of them allocate once per request. Generated id, attributes of records
+// grouped by requestHandler and values of headers set by writeResponse are stored in it as well
+type requestInfo struct {
+	context.Context
+	id       string
+	logger   atomic.Pointer[slog.Logger]
+	request  *http.Request
+	handler  *handlerOptions
+	recorder ResponseRecorder
+
+	idBuf        [36]byte
+	attrs        [groupAttrs]slog.Attr
+	attrsUsed    atomic.Int32
+	headerValues [2]string
+	headersUsed  bool
+}
+
+var requestInfoKey = NewContextKey[*requestInfo]("request")
+
+func (i *requestInfo) Value(key any) any {
+	if key == any(requestInfoKey) {
+		return i
+	}
+	return i.Context.Value(key)
+}
+
+// responseHeaderValues returns storage for values of Content-Type and Content-Length headers of the response,
+// the storage of requestInfo is returned once, so values of another response are never overwritten
+func responseHeaderValues(r *http.Request) []string {
+	if info, ok := requestInfoKey.Value(r.Context()); ok && !info.headersUsed {
+		info.headersUsed = true
+		return info.headerValues[:]
+	}
+	return make([]string, 2)
+}
+
+// groupLogger returns logger grouped by id of the request, it is created once when it is asked
+func (i *requestInfo) groupLogger() *slog.Logger {
+	if logger := i.logger.Load(); logger != nil {
+		return logger
+	}
+
+	logger := i.handler.logger
+	if i.id != "" {
+		logger = logger.WithGroup(i.id)
+	}
+	if i.logger.CompareAndSwap(nil, logger) {
+		return logger
+	}
+	return i.logger.Load()
+}
+
+// groupAttrs is a number of attributes of records grouped by requestHandler without allocation
+const groupAttrs = 8
+
+// attrSlots returns empty slice with capacity of n attributes. Slots of requestInfo are never reused,
+// so handlers may keep records after Handle returns
+func (i *requestInfo) attrSlots(n int) []slog.Attr {
+	if end := int(i.attrsUsed.Add(int32(n))); end <= len(i.attrs) {
+		return i.attrs[end-n : end-n : end]
+	}
+	return make([]slog.Attr, 0, n)
+}
+
+// requestHandler nests attributes of records under id of the request found in their context, as the handler
+// returned by WithGroup does, so logs of handlers are grouped without cloning logger for every request.
+// Attributes and groups added with WithAttrs and WithGroup are not nested, handlers of the package log
+// with its logger only
+type requestHandler struct {
+	slog.Handler
+}
+
+func (h *requestHandler) Handle(ctx context.Context, r slog.Record) error {
+	info, ok := requestInfoKey.Value(ctx)
+	if !ok || info.id == "" || r.NumAttrs() == 0 {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	attrs := info.attrSlots(r.NumAttrs())
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	grouped := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	grouped.AddAttrs(slog.Attr{Key: info.id, Value: slog.GroupValue(attrs...)})
+
+	return h.Handler.Handle(ctx, grouped)
+}
+
+func (h *requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *requestHandler) WithGroup(name string) slog.Handler {
+	return &requestHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// newRequestID generates random uuid as [uuid.NewString] does, formatting it into buf so the id does not
+// allocate when buf is a part of requestInfo
+func newRequestID(buf *[36]byte) string {
+	var id [16]byte
+	_, _ = cryptorand.Read(id[:])
+	id[6] = (id[6] & 0x0f) | 0x40 // version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // variant 10
+
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+
+	return unsafe.String(&buf[0], len(buf))
+}
+
+// RequestIDFromContext returns id grouping logs of the request, it is set for Bind, use case
+// and middlewares set with WithMiddleware
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if info, ok := requestInfoKey.Value(ctx); ok {
+		return info.id, info.id != ""
+	}
+	return "", false
+}
+
+// RequestFromContext returns [http.Request] served by handler, e.g. to read RemoteAddr or TLS state in use case.
+// Request must not be used after the handler returns. Its context is not cancelled by WithTimeout,
+// use the context passed to use case instead
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	if info, ok := requestInfoKey.Value(ctx); ok {
+		return info.request, true
+	}
+	return nil, false
+}
+
+// A HandlerInfo describes handler serving the request. ContentType is a content type of the encoder set by
+// options or negotiated for the request, responses like Raw may be sent with other content type.
+// Route is a pattern of [http.ServeMux] matched by the request, empty when the handler is served without mux
+type HandlerInfo struct {
+	SuccessCode int
+	ContentType string
+	Route       string
+}
+
+// HandlerInfoFromContext returns description of the handler serving the request, e.g. to label metrics in middlewares
+// set with WithMiddleware
+//
+// Usage:
+//
+//	func metrics(next http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			next.ServeHTTP(w, r)
+//			if info, ok := httpx.HandlerInfoFromContext(r.Context()); ok {
+//				requests.WithLabelValues(info.Route, info.ContentType).Inc()
+//			}
+//		})
+//	}
+func HandlerInfoFromContext(ctx context.Context) (HandlerInfo, bool) {
+	info, ok := requestInfoKey.Value(ctx)
+	if !ok || info.handler == nil {
+		return HandlerInfo{}, false
+	}
+
+	var contentType string
+	if contentTyper, ok := info.handler.encoderFor(info.request).(encoder.ContentTyper); ok {
+		contentType = contentTyper.ContentType()
+	}
+
+	return HandlerInfo{
+		SuccessCode: info.handler.successCode,
+		ContentType: contentType,
+		Route:       info.request.Pattern,
+	}, true
+}
+
+// RouteOf returns path of the matched [http.ServeMux] pattern without method and host, e.g. to label metrics and spans.
+// Empty path is returned for requests served without mux
+func RouteOf(r *http.Request) string {
+	_, route, ok := strings.Cut(r.Pattern, " ")
+	if !ok {
+		route = r.Pattern
+	}
+
+	if i := strings.Index(route, "/"); i > 0 {
+		route = route[i:]
+	}
+
+	return route
+}
+
+// observeError passes err to observers set by WithErrorObserver
+func (h *handlerOptions) observeError(r *http.Request, err error) {
+	for _, observer := range h.errorObservers {
+		observer(r, err)
+	}
+}
+
+// requestID returns id to group logs of the request. Id from X-Request-ID header is reused,
+// then id returned by WithRequestIDFunc, otherwise a new id is generated into buf. Empty id is returned when ids are disabled with WithoutRequestID
+func (h *handlerOptions) requestID(r *http.Request, buf *[36]byte) string {
+	if info, ok := requestInfoKey.Value(r.Context()); ok {
+		return info.id
+	}
+
+	if h.noRequestID {
+		return ""
+	}
+
+	if values := r.Header[requestIDKey]; len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+
+	if h.idFunc != nil {
+		if id := h.idFunc(r); id != "" {
+			return id
+		}
+	}
+
+	if h.idGenerator != nil {
+		return h.idGenerator()
+	}
+	return newRequestID(buf)
+}
+
+// LoggerFromContext returns logger of the request grouped by request id, so logs of use cases are correlated
+// with logs of the handler. [slog.Default] is returned for contexts of other requests
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if info, ok := requestInfoKey.Value(ctx); ok {
+		return info.groupLogger()
+	}
+	return slog.Default()
+}
+
+// loggerFor returns logger grouping records logged with context of the request by id resolved by logHandler
+func (h *handlerOptions) loggerFor(r *http.Request) *slog.Logger {
+	if _, ok := requestInfoKey.Value(r.Context()); ok {
+		return h.requestLogger
+	}
+	return h.logger.WithGroup(h.requestID(r, new([36]byte)))
+}
+
+// wrap applies response writer wrappers configured by options to the handler
+func (h *handlerOptions) wrap(next http.HandlerFunc) http.HandlerFunc {
+	if len(h.contextFuncs) > 0 {
+		next = contextHandler(h.contextFuncs, next)
+	}
+
+	if h.gzip {
+		next = gzipHandler(h.gzipLevel, next)
+	}
+
+	if h.registry != nil {
+		next = varyHandler("Accept", next)
+	}
+
+	next = h.charsetHandler(next)
+
+	if h.formatParam != "" && !h.encoderSet {
+		next = h.formatHandler(next)
+	}
+
+	if len(h.middlewares) > 0 {
+		next = Chain(h.middlewares...)(next).ServeHTTP
+	}
+
+	if h.semaphore != nil {
+		next = concurrencyHandler(h.semaphore, h.overflow, next)
+	}
+
+	if h.timeout > 0 {
+		next = timeoutHandler(h.timeout, next)
+	}
+
+	next = h.logHandler(next)
+
+	if len(h.outer) > 0 {
+		next = Chain(h.outer...)(next).ServeHTTP
+	}
+
+	return next
+}
+
+// timeoutHandler passes request with context cancelled after d to next handler, writes fail after the deadline
+// except of error response
+func timeoutHandler(d time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+
+		next(NewContextWriter(ctx, w), r.WithContext(ctx))
+	}
+}
+
+// logHandler resolves request id once for all logs of the request and logs completion of next handler
+// with its duration and status
+func (h *handlerOptions) logHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			info   = &requestInfo{Context: r.Context(), request: r, handler: h}
+			logger = h.requestLogger
+			start  = h.now()
+		)
+
+		info.id = h.requestID(r, &info.idBuf)
+		info.recorder.ResponseWriter = w
+		rec := &info.recorder
+		next(rec, r.WithContext(info))
+
+		var (
+			duration = h.now().Sub(start)
+			attrs    = [...]slog.Attr{
+				slog.Duration("duration", duration),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.Status()),
+			}
+		)
+
+		logger.LogAttrs(info, slog.LevelInfo, "completed", attrs[:]...)
+		if h.slowAfter > 0 && duration > h.slowAfter {
+			logger.LogAttrs(info, slog.LevelWarn, "slow request", attrs[:]...)
+		}
+	}
+}
+
+// contextHandler passes request with context enriched by fns to next handler
+func contextHandler(fns []func(context.Context, *http.Request) context.Context, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		for _, fn := range fns {
+			ctx = fn(ctx, r)
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
 // Handle receives request and response structs as type parameters to pass to use case function.
 // Using options you can add your custom response codes and encoders to handler.
 //
@@ -113,80 +926,531 @@ func applyOptions(options ...Option) handlerOptions {
 //
 //	mux.HandleFunc("GET /", httpx.Handle[Request, Response](handleIndex, httpx.WithSuccessCode(http.StatusBadRequest), httpx.WithLogger(logger)))
 func Handle[Req any, Resp any, _Req Request[Req]](useCase UseCaseFunc[Req, Resp], options ...Option) http.HandlerFunc {
+	return handle[Req, Resp, _Req](applyOptions(options...), useCase)
+}
+
+// handle creates handler of use case with options already applied
+func handle[Req any, Resp any, _Req Request[Req]](h handlerOptions, useCase UseCaseFunc[Req, Resp]) http.HandlerFunc {
+	return h.wrap(func(w http.ResponseWriter, r *http.Request) {
+		var logger = h.loggerFor(r)
+
+		req, ok := bind[Req, _Req](&h, w, r, logger)
+		if !ok {
+			return
+		}
+
+		response, err := useCase(r.Context(), req)
+		if err != nil {
+			h.writeUseCaseError(w, r, err)
+			return
+		}
+
+		h.writeResponse(w, r, logger, response)
+	})
+}
+
+// HandleNoReq is a variant of Handle for endpoints without request. Binding and validation are skipped,
+// use case result is written with the same options as in Handle
+//
+// Usage:
+//
+//	mux.HandleFunc("GET /health", httpx.HandleNoReq(healthUseCase.Health))
+func HandleNoReq[Resp any](useCase func(context.Context) (Resp, error), options ...Option) http.HandlerFunc {
 	var h = applyOptions(options...)
 
-	return func(w http.ResponseWriter, r *http.Request) {
-		var (
-			id   = uuid.New().String()
-			req  Req
-			_req = _Req(&req)
-			err  error
-		)
+	return h.wrap(func(w http.ResponseWriter, r *http.Request) {
+		var logger = h.loggerFor(r)
+
+		response, err := useCase(r.Context())
+		if err != nil {
+			h.writeUseCaseError(w, r, err)
+			return
+		}
+
+		h.writeResponse(w, r, logger, response)
+	})
+}
+
+// HandleNoResp is a variant of Handle for endpoints without response body. Request is bound and validated as in Handle,
+// on success only status code is written. Default success code is a [http.StatusNoContent]
+//
+// Usage:
+//
+//	mux.HandleFunc("DELETE /users/{id}", httpx.HandleNoResp[DeleteRequest](deleteUser))
+func HandleNoResp[Req any, _Req Request[Req]](useCase func(context.Context, Req) error, options ...Option) http.HandlerFunc {
+	var h = applyOptions(append([]Option{WithSuccessCode(http.StatusNoContent)}, options...)...)
+
+	return h.wrap(func(w http.ResponseWriter, r *http.Request) {
+		var logger = h.loggerFor(r)
+
+		req, ok := bind[Req, _Req](&h, w, r, logger)
+		if !ok {
+			return
+		}
 
-		err = _req.Bind(r)
+		err := useCase(r.Context(), req)
 		if err != nil {
-			if errx, ok := errorsx.As(err); ok && !errx.Internal() {
-				h.logger.WithGroup(id).Error("failed to bind request", slog.Any("err", errx))
-				w.WriteHeader(errx.Code())
-				err = h.encoder.New(w).Encode(DefaultResponse{Message: err.Error()})
-				if err != nil {
-					h.logger.WithGroup(id).Error("failed to write error response", err)
-					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			h.writeUseCaseError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(h.successCode)
+	})
+}
+
+// HandleUpgrade is a variant of Handle for endpoints taking over the connection, e.g. WebSocket endpoints of wsx package.
+// Request is bound and validated as in Handle, errors are written as in Handle. Then upgrade is called to write
+// the response itself, logger of the request is returned by LoggerFromContext
+//
+// Usage:
+//
+//	mux.HandleFunc("GET /tunnel", httpx.HandleUpgrade[Request](func(w http.ResponseWriter, r *http.Request, req Request) {
+//		conn, rw, err := http.NewResponseController(w).Hijack()
+//		...
+//	}))
+func HandleUpgrade[Req any, _Req Request[Req]](upgrade func(w http.ResponseWriter, r *http.Request, req Req), options ...Option) http.HandlerFunc {
+	var h = applyOptions(options...)
+
+	return h.wrap(func(w http.ResponseWriter, r *http.Request) {
+		req, ok := bind[Req, _Req](&h, w, r, h.loggerFor(r))
+		if !ok {
+			return
+		}
+
+		upgrade(w, r, req)
+	})
+}
+
+// bind binds and validates request, on failure error response is written and false is returned
+func bind[Req any, _Req Request[Req]](h *handlerOptions, w http.ResponseWriter, r *http.Request, logger *slog.Logger) (Req, bool) {
+	var (
+		req  Req
+		_req = _Req(&req)
+		err  error
+	)
+
+	var start time.Time
+	if h.bindObserver != nil {
+		start = h.now()
+	}
+
+	if h.maxBodySize > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+	}
+
+	err = h.checkBodySize(r)
+	if err == nil {
+		err = h.checkContentType(r)
+	}
+	if err == nil {
+		if streamBindable, ok := any(_req).(StreamBindable); ok {
+			var body io.Reader = http.NoBody
+			if r.Body != nil {
+				body = r.Body
+			}
+			err = streamBindable.BindStream(r.Context(), body)
+		} else {
+			err = _req.Bind(r)
+		}
+	}
+	if h.bindObserver != nil {
+		h.bindObserver(h.now().Sub(start), err)
+	}
+	if err != nil {
+		h.observeError(r, err)
+		if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+			err = errorsx.New(false, http.StatusRequestEntityTooLarge, maxBytesErr.Error())
+		} else if _, ok := errorsx.As(err); !ok {
+			err = errorsx.New(false, h.bindCode, err.Error())
+		}
+		if errx, ok := errorsx.As(err); ok && !errx.Internal() {
+			var (
+				body    = errorxBody(errx, DefaultResponse{Message: err.Error()})
+				sampled = h.sampleErrorLog()
+			)
+			if vErr, ok := errorsx.AsValidation(err); ok {
+				if sampled {
+					logger.WarnContext(r.Context(), "failed to bind request", validationAttrs(vErr)...)
 				}
-				return
+				body = vErr
+			} else if sampled {
+				logger.ErrorContext(r.Context(), "failed to bind request", slog.Any("err", errx))
 			}
-			w.WriteHeader(http.StatusInternalServerError)
-			err = h.encoder.New(w).Encode(DefaultResponse{Message: http.StatusText(http.StatusInternalServerError)})
+			setRetryAfter(w, errx)
+			err = h.writeError(w, r, errx.Code(), body)
 			if err != nil {
-				h.logger.WithGroup(id).Error("failed to write error response", err)
+				logger.ErrorContext(r.Context(), "failed to write error response", slog.Any("err", err))
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			}
-			return
+			return req, false
 		}
+		logger.ErrorContext(r.Context(), "failed to bind request", errorAttrs(err)...)
+		h.writeInternalError(w, r, logger)
+		return req, false
+	}
 
-		h.logger.WithGroup(id).Info("request", _req)
+	if !h.logAfterValidate {
+		logger.LogAttrs(r.Context(), slog.LevelInfo, "request", slog.Any("request", _req), slog.Bool("validated", false))
+	}
 
-		err = _req.Validate()
-		if err != nil {
-			if errx, ok := errorsx.As(err); ok && !errx.Internal() {
-				h.logger.WithGroup(id).Error("failed to validate request", slog.Any("err", errx))
-				w.WriteHeader(errx.Code())
-				err = h.encoder.New(w).Encode(errx.Error())
-				if err != nil {
-					h.logger.WithGroup(id).Error("failed to write error response", err)
-					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	if h.validateObserver != nil {
+		start = h.now()
+	}
+	err = _req.Validate()
+	if err == nil && h.validator != nil {
+		err = h.validator.Validate(_req)
+	}
+	if h.validateObserver != nil {
+		h.validateObserver(h.now().Sub(start), err)
+	}
+	if _, ok := errorsx.As(err); err != nil && !ok {
+		if h.validateCode == http.StatusUnprocessableEntity {
+			err = &errorsx.ValidationError{Message: err.Error()}
+		} else {
+			err = errorsx.New(false, h.validateCode, err.Error())
+		}
+	}
+	if err != nil {
+		h.observeError(r, err)
+		if errx, ok := errorsx.As(err); ok && !errx.Internal() {
+			var (
+				body    = errorxBody(errx, errx.Error())
+				sampled = h.sampleErrorLog()
+			)
+			if vErr, ok := errorsx.AsValidation(err); ok {
+				if sampled {
+					logger.WarnContext(r.Context(), "failed to validate request", validationAttrs(vErr)...)
 				}
-				return
+				body = vErr
+			} else if sampled {
+				logger.ErrorContext(r.Context(), "failed to validate request", slog.Any("err", errx))
+			}
+			setRetryAfter(w, errx)
+			err = h.writeError(w, r, errx.Code(), body)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "failed to write error response", slog.Any("err", err))
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			}
+			return req, false
+		}
+		logger.ErrorContext(r.Context(), "failed to validate request", errorAttrs(err)...)
+		h.writeInternalError(w, r, logger)
+		return req, false
+	}
+
+	if h.logAfterValidate {
+		logger.LogAttrs(r.Context(), slog.LevelInfo, "request", slog.Any("request", _req))
+	}
+
+	return req, true
+}
+
+// checkBodySize returns [http.StatusRequestEntityTooLarge] error when request declares body larger than
+// the limit set by WithMaxBodySize
+func (h *handlerOptions) checkBodySize(r *http.Request) error {
+	if h.maxBodySize <= 0 || r.ContentLength <= h.maxBodySize {
+		return nil
+	}
+
+	return errorsx.New(false, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body too large, limit is %d bytes", h.maxBodySize))
+}
+
+// checkContentType returns [http.StatusUnsupportedMediaType] error when request body has not accepted content type
+func (h *handlerOptions) checkContentType(r *http.Request) error {
+	if len(h.contentTypes) == 0 || !hasBody(r) {
+		return nil
+	}
+
+	contentType := mediaType(r.Header.Get("Content-Type"))
+	if slices.Contains(h.contentTypes, contentType) {
+		return nil
+	}
+
+	return errorsx.New(false, http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type %q", contentType))
+}
+
+func (h *handlerOptions) writeUseCaseError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+		h.loggerFor(r).InfoContext(r.Context(), "request canceled by client", slog.Any("err", err))
+		recordStatus(w, StatusClientClosedRequest)
+		return
+	}
+
+	h.observeError(r, err)
+	if errors.Is(err, context.DeadlineExceeded) && r.Context().Err() != nil {
+		h.loggerFor(r).WarnContext(r.Context(), "request timed out", slog.Any("err", err))
+		body := DefaultResponse{Message: http.StatusText(http.StatusServiceUnavailable), RequestID: h.requestID(r, new([36]byte))}
+		if err = h.writeError(w, r, http.StatusServiceUnavailable, body); err != nil {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		}
+		return
+	}
+	if errx, ok := errorsx.As(err); ok && !errx.Internal() {
+		setRetryAfter(w, errx)
+		err = h.writeError(w, r, errx.Code(), errorxBody(errx, errx.Error()))
+		if err != nil {
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	h.loggerFor(r).ErrorContext(r.Context(), "failed to handle request", errorAttrs(err)...)
+	h.writeInternalError(w, r, h.loggerFor(r))
+}
+
+// errorxBody returns [errorsx.ErrorResponse] of errx when it has code set by [errorsx.Errorx.WithErrorCode], otherwise body
+func errorxBody(errx *errorsx.Errorx, body any) any {
+	if errx.ErrorCode() != "" {
+		return errx.Response()
+	}
+	return body
+}
+
+// writeInternalError writes [http.StatusInternalServerError] response with body set by WithInternalErrorBody
+func (h *handlerOptions) writeInternalError(w http.ResponseWriter, r *http.Request, logger *slog.Logger) {
+	var (
+		id   = h.requestID(r, new([36]byte))
+		body any
+	)
+
+	if h.internalErrorBody != nil {
+		body = h.internalErrorBody(id)
+	} else {
+		body = DefaultResponse{Message: http.StatusText(http.StatusInternalServerError), RequestID: id}
+	}
+
+	if err := h.writeError(w, r, http.StatusInternalServerError, body); err != nil {
+		logger.ErrorContext(r.Context(), "failed to write error response", slog.Any("err", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// sampleErrorLog reports whether error which is not internal should be logged with rate set by WithErrorLogSampling
+func (h *handlerOptions) sampleErrorLog() bool {
+	if !h.errorLogSampling || h.errorLogRate >= 1 {
+		return true
+	}
+
+	return h.random() < h.errorLogRate
+}
+
+// validationAttrs returns log attributes of validation error with fields group mapping every invalid field
+// to its message, so logs can be searched by field
+func validationAttrs(vErr *errorsx.ValidationError) []any {
+	fields := make([]any, 0, len(vErr.Fields))
+	for _, field := range vErr.Fields {
+		fields = append(fields, slog.String(field.Field, field.Message))
+	}
+
+	return []any{slog.String("err", vErr.Message), slog.Group("fields", fields...)}
+}
+
+// errorAttrs returns log attributes of err with stack trace recorded by [errorsx.Errorx.WithStack]
+func errorAttrs(err error) []any {
+	attrs := []any{slog.Any("err", err)}
+	if errx, ok := errorsx.As(err); ok {
+		if stack := errx.StackTrace(); stack != "" {
+			attrs = append(attrs, slog.String("stack", stack))
+		}
+	}
+	return attrs
+}
+
+// setRetryAfter sets Retry-After header in seconds when errx carries retry duration
+func setRetryAfter(w http.ResponseWriter, errx *errorsx.Errorx) {
+	if retryAfter := errx.RetryAfter(); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+}
+
+// writeError writes error body with code using encoder set by WithErrorEncoder or negotiated one,
+// Content-Type header is set from the encoder. Bodies the encoder can not encode, e.g. DefaultResponse with CSV encoder,
+// are written as JSON. Error is returned only when nothing was written, failures of writing the body are ignored
+func (h *handlerOptions) writeError(w http.ResponseWriter, r *http.Request, code int, body any) error {
+	enc := h.errorEncoder
+	if enc == nil {
+		enc = h.encoderFor(r)
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
+
+	if err := encode(enc, buf, body); err != nil {
+		buf.Reset()
+		if enc = encoder.JsonEncoder; enc.New(buf).Encode(body) != nil {
+			return err
+		}
+	}
+
+	headerValues := responseHeaderValues(r)
+	if contentTyper, ok := enc.(encoder.ContentTyper); ok && contentTyper.ContentType() != "" {
+		headerValues[0] = contentTyper.ContentType()
+		w.Header()["Content-Type"] = headerValues[0:1:1]
+	}
+	allowFinalWrite(w)
+	w.WriteHeader(code)
+	_, _ = buf.WriteTo(w)
+
+	return nil
+}
+
+func (h *handlerOptions) writeResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, response any) {
+	var (
+		enc  = h.encoderFor(r)
+		code = h.successCode
+		body = response
+	)
+
+	if result, ok := response.(resultValuer); ok {
+		body = result.resultValue()
+		setHeaders(w, response)
+	}
+	if statusCoder, ok := response.(StatusCoder); ok && statusCoder.StatusCode() > 0 {
+		code = statusCoder.StatusCode()
+	}
+	if file, ok := body.(FileResponse); ok {
+		h.writeFile(w, r, logger, code, file)
+		return
+	}
+	if raw, ok := body.(Raw); ok {
+		enc = newRawEncoder(raw)
+	} else if e, ok := h.responseEncoder(body); ok {
+		enc = e
+	}
+
+	logger.LogAttrs(r.Context(), slog.LevelInfo, "response", slog.Any("response", body))
+
+	headerValues := responseHeaderValues(r)
+	if contentTyper, ok := enc.(encoder.ContentTyper); ok && contentTyper.ContentType() != "" {
+		headerValues[0] = contentTyper.ContentType()
+		w.Header()["Content-Type"] = headerValues[0:1:1]
+	}
+	setHeaders(w, body)
+	setCacheHeaders(w, body)
+	if writeNotModified(w, r, body) {
+		return
+	}
+
+	if h.preferHandling {
+		w.Header().Add("Vary", "Prefer")
+		if prefersMinimal(r) {
+			w.Header().Set("Preference-Applied", "return=minimal")
+			w.Header().Del("Content-Type")
+			w.WriteHeader(code)
 			return
 		}
+	}
 
-		response, err := useCase(r.Context(), req)
+	if _, ok := body.(Raw); !ok && h.responseWrapper != nil {
+		wrapped, err := h.responseWrapper(r.Context(), body)
 		if err != nil {
-			if errx, ok := errorsx.As(err); ok && !errx.Internal() {
-				w.WriteHeader(errx.Code())
-				err = h.encoder.New(w).Encode(errx.Error())
-				if err != nil {
-					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-				}
-				return
-			}
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			h.writeUseCaseError(w, r, err)
 			return
 		}
+		body = wrapped
+	}
 
-		h.logger.WithGroup(id).Info("response", response)
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
 
-		w.WriteHeader(h.successCode)
-		err = h.encoder.New(w).Encode(response)
-		if err != nil {
-			if errx, ok := errorsx.As(err); ok && !errx.Internal() {
-				http.Error(w, errx.Error(), errx.Code())
-				return
-			}
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	if err := encode(enc, buf, body); err != nil {
+		logger.ErrorContext(r.Context(), "failed to encode response", slog.Any("err", err))
+		if errx, ok := errorsx.As(err); ok && !errx.Internal() {
+			http.Error(w, errx.Error(), errx.Code())
+			return
+		}
+		h.writeInternalError(w, r, logger)
+		return
+	}
+
+	bodyAllowed := code != http.StatusNoContent && code != http.StatusNotModified && code >= http.StatusOK
+	if bodyAllowed && (!h.gzip || !acceptsGzip(r)) {
+		headerValues[1] = strconv.Itoa(buf.Len())
+		w.Header()["Content-Length"] = headerValues[1:2:2]
+	}
+	w.WriteHeader(code)
+	if !bodyAllowed || r.Method == http.MethodHead {
+		return
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		if connectionClosed(err) {
+			logger.DebugContext(r.Context(), "client closed connection", slog.Any("err", err))
 			return
 		}
+		logger.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+// encode writes src encoded by enc into w, encoders implementing [encoder.WriterEncoder] are used without New
+func encode(enc encoder.Encoder, w io.Writer, src any) error {
+	if writerEncoder, ok := enc.(encoder.WriterEncoder); ok {
+		return writerEncoder.EncodeTo(w, src)
+	}
+	return enc.New(w).Encode(src)
+}
+
+// connectionClosed reports whether writing failed because client went away, such errors are not server faults
+func connectionClosed(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, net.ErrClosed) || errors.Is(err, context.Canceled)
+}
+
+// maxPooledBufferSize is a capacity of buffers above which they are dropped instead of returning to bufferPool,
+// so a single large response does not keep memory forever
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool holds buffers to encode responses into
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// responseEncoder returns encoder registered for content type of response implementing [encoder.ContentTyper],
+// responses are not asked when encoder is set explicitly by WithEncoderFunc
+func (h *handlerOptions) responseEncoder(response any) (encoder.Encoder, bool) {
+	contentTyper, ok := response.(encoder.ContentTyper)
+	if !ok || h.encoderSet {
+		return nil, false
+	}
+
+	registry := h.registry
+	if registry == nil {
+		registry = encoder.DefaultRegistry
+	}
+
+	return registry.Lookup(contentTyper.ContentType())
+}
+
+// prefersMinimal reports whether request has Prefer: return=minimal header
+func prefersMinimal(r *http.Request) bool {
+	for _, value := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(value, ",") {
+			preference, _, _ = strings.Cut(preference, ";")
+			if strings.EqualFold(strings.ReplaceAll(preference, " ", ""), "return=minimal") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// setHeaders copies headers of response implementing HeaderCarrier
+func setHeaders(w http.ResponseWriter, response any) {
+	headerCarrier, ok := response.(HeaderCarrier)
+	if !ok {
+		return
+	}
+
+	for key, values := range headerCarrier.Header() {
+		w.Header()[http.CanonicalHeaderKey(key)] = values
 	}
 }
@@ -0,0 +1,175 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestMain(m *testing.M) {
+	httpx.SetDefaults(httpx.WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))))
+	os.Exit(m.Run())
+}
+
+type emptyRequest struct {
+	httpx.DefaultRequest
+}
+
+func (emptyRequest) String() string {
+	return "empty"
+}
+
+func reply(body string) httpx.UseCaseFunc[emptyRequest, string] {
+	return func(context.Context, emptyRequest) (string, error) {
+		return body, nil
+	}
+}
+
+// pathRequest is bound from path value id
+type pathRequest struct {
+	httpx.DefaultRequest
+	ID string
+}
+
+func (r *pathRequest) Bind(req *http.Request) error {
+	r.ID = req.PathValue("id")
+	return nil
+}
+
+func (r pathRequest) String() string {
+	return r.ID
+}
+
+// userRequest is bound from JSON body
+type userRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *userRequest) Bind(req *http.Request) error {
+	return httpx.BindJSON(req, r)
+}
+
+func (r *userRequest) Validate() error {
+	return nil
+}
+
+func (r userRequest) String() string {
+	return r.Name
+}
+
+func greet(_ context.Context, req userRequest) (string, error) {
+	return "hello " + req.Name, nil
+}
+
+// post serves request with body of given content type
+func post(h http.Handler, target, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, target, body)
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+var errNotFound = errorsx.New(false, http.StatusNotFound, "not found")
+
+func TestRequestFromContext(t *testing.T) {
+	var (
+		remoteAddr string
+		found      bool
+		handler    = httpx.Handle(func(ctx context.Context, _ emptyRequest) (string, error) {
+			var r *http.Request
+			if r, found = httpx.RequestFromContext(ctx); found {
+				remoteAddr = r.RemoteAddr
+			}
+			return "ok", nil
+		}, httpx.WithTimeout(time.Second))
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:4321"
+	handler(httptest.NewRecorder(), r)
+
+	if !found || remoteAddr != "203.0.113.7:4321" {
+		t.Errorf("RequestFromContext() remote addr = %q, found %v", remoteAddr, found)
+	}
+	if _, ok := httpx.RequestFromContext(context.Background()); ok {
+		t.Error("RequestFromContext() found request in background context")
+	}
+}
+
+// slowBindRequest fails to bind after a delay
+type slowBindRequest struct {
+	httpx.DefaultRequest
+}
+
+func (*slowBindRequest) Bind(*http.Request) error {
+	time.Sleep(5 * time.Millisecond)
+	return errNotFound
+}
+
+func (slowBindRequest) String() string {
+	return "slow"
+}
+
+// observation is a call of bind or validate observer
+type observation struct {
+	d   time.Duration
+	err error
+}
+
+func TestBindObservers(t *testing.T) {
+	var binds, validations []observation
+	options := []httpx.Option{
+		httpx.WithBindObserver(func(d time.Duration, err error) {
+			binds = append(binds, observation{d: d, err: err})
+		}),
+		httpx.WithValidateObserver(func(d time.Duration, err error) {
+			validations = append(validations, observation{d: d, err: err})
+		}),
+	}
+
+	t.Run("bind fails", func(t *testing.T) {
+		binds, validations = nil, nil
+		serve(httpx.Handle(func(context.Context, slowBindRequest) (string, error) {
+			return "", nil
+		}, options...), http.MethodGet, "/")
+
+		if len(binds) != 1 || binds[0].d < 5*time.Millisecond || binds[0].d > time.Second || !errors.Is(binds[0].err, errNotFound) {
+			t.Errorf("bind observations = %v, want one of at least 5ms with error", binds)
+		}
+		if len(validations) != 0 {
+			t.Errorf("validate observations = %v, want none after failed bind", validations)
+		}
+	})
+
+	t.Run("validate fails", func(t *testing.T) {
+		binds, validations = nil, nil
+		serve(httpx.Handle(func(context.Context, badValidateRequest) (string, error) {
+			return "", nil
+		}, options...), http.MethodGet, "/")
+
+		if len(binds) != 1 || binds[0].err != nil || binds[0].d < 0 {
+			t.Errorf("bind observations = %v, want one without error", binds)
+		}
+		if len(validations) != 1 || validations[0].err == nil || validations[0].d < 0 {
+			t.Errorf("validate observations = %v, want one with error", validations)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package httpx
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader is a header carrying client generated key of the request which is safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyTTL is a duration for which Idempotency middleware keeps captured responses
+	IdempotencyTTL = 24 * time.Hour
+)
+
+// A CachedResponse is a response captured by Idempotency middleware
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// An IdempotencyStore keeps captured responses by idempotency key. Implementations must be safe for concurrent use
+type IdempotencyStore interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, response CachedResponse, ttl time.Duration)
+}
+
+// Idempotency returns middleware replaying the stored response for requests with already seen Idempotency-Key header
+// instead of calling the handler. Keys are scoped by method and path, requests with the same key are serialized,
+// so retries sent before the first request completes wait for its response. Responses with 5xx status are not
+// stored to let clients retry them. When store is nil, in-memory store is used
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithMiddleware(httpx.Idempotency(nil)))
+func Idempotency(store IdempotencyStore) Middleware {
+	if store == nil {
+		store = NewMemoryIdempotencyStore()
+	}
+
+	var locks keyedMutex
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key = r.Method + " " + r.URL.Path + " " + key
+
+			unlock := locks.lock(key)
+			defer unlock()
+
+			if cached, ok := store.Get(key); ok {
+				for k, values := range cached.Header {
+					w.Header()[k] = values
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(cached.Status)
+				_, _ = w.Write(cached.Body)
+				return
+			}
+
+			capture := &captureWriter{ResponseRecorder: ResponseRecorder{ResponseWriter: w}}
+			next.ServeHTTP(capture, r)
+
+			status := capture.Status()
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			store.Set(key, CachedResponse{
+				Status: status,
+				Header: w.Header().Clone(),
+				Body:   capture.body.Bytes(),
+			}, IdempotencyTTL)
+		})
+	}
+}
+
+// captureWriter records status code and body written to the wrapped [http.ResponseWriter]
+type captureWriter struct {
+	ResponseRecorder
+	body bytes.Buffer
+}
+
+func (c *captureWriter) Write(b []byte) (int, error) {
+	n, err := c.ResponseRecorder.Write(b)
+	c.body.Write(b[:n])
+	return n, err
+}
+
+// keyedMutex serializes callers of lock with the same key, mutexes of unused keys are released
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+type memoryEntry struct {
+	response  CachedResponse
+	expiresAt time.Time
+}
+
+// A MemoryIdempotencyStore is an in-memory IdempotencyStore, expired responses are evicted periodically
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryIdempotencyStore creates empty MemoryIdempotencyStore
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Get(key string) (CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return CachedResponse{}, false
+	}
+
+	return entry.response, true
+}
+
+func (s *MemoryIdempotencyStore) Set(key string, response CachedResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.lastSweep = now
+		for k, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	s.entries[key] = memoryEntry{response: response, expiresAt: now.Add(ttl)}
+}
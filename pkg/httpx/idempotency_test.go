@@ -0,0 +1,81 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestIdempotency(t *testing.T) {
+	var (
+		calls int
+		fail  bool
+	)
+	handler := httpx.Handle[emptyRequest, string](func(context.Context, emptyRequest) (string, error) {
+		calls++
+		if fail {
+			return "", errors.New("db is down")
+		}
+		return fmt.Sprintf("order %d", calls), nil
+	}, httpx.WithSuccessCode(http.StatusCreated), httpx.WithMiddleware(httpx.Idempotency(nil)))
+
+	request := func(path, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		if key != "" {
+			r.Header.Set(httpx.IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	first := request("/orders", "k1")
+	replay := request("/orders", "k1")
+
+	if calls != 1 {
+		t.Errorf("use case calls = %d, want 1", calls)
+	}
+	if replay.Code != http.StatusCreated || replay.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", replay.Code, replay.Body.String(), http.StatusCreated, first.Body.String())
+	}
+	if replay.Header().Get("Idempotent-Replayed") != "true" || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("only replayed response should carry Idempotent-Replayed header")
+	}
+	if got, want := replay.Header().Get("Content-Type"), first.Header().Get("Content-Type"); got != want {
+		t.Errorf("replayed Content-Type = %q, want %q", got, want)
+	}
+
+	request("/orders", "k2")
+	request("/carts", "k1")
+	request("/orders", "")
+	request("/orders", "")
+	if calls != 5 {
+		t.Errorf("use case calls = %d, want 5 for other keys, paths and requests without key", calls)
+	}
+
+	fail = true
+	request("/orders", "k3")
+	fail = false
+	if w := request("/orders", "k3"); w.Code != http.StatusCreated || calls != 7 {
+		t.Errorf("retry after server error = %d with %d calls, want the handler called again", w.Code, calls)
+	}
+}
+
+func TestMemoryIdempotencyStoreExpiry(t *testing.T) {
+	store := httpx.NewMemoryIdempotencyStore()
+	store.Set("live", httpx.CachedResponse{Status: http.StatusOK}, time.Hour)
+	store.Set("expired", httpx.CachedResponse{Status: http.StatusOK}, -time.Second)
+
+	if _, ok := store.Get("live"); !ok {
+		t.Error("live response is not found")
+	}
+	if _, ok := store.Get("expired"); ok {
+		t.Error("expired response is found")
+	}
+}
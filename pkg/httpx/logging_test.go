@@ -0,0 +1,312 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type loginRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+func (r *loginRequest) Bind(req *http.Request) error {
+	return httpx.BindJSON(req, r)
+}
+
+func (r *loginRequest) Validate() error {
+	return nil
+}
+
+func (r loginRequest) String() string {
+	return r.Login
+}
+
+func (r loginRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("login", r.Login), slog.String("password", "***"))
+}
+
+// bufferLogger returns logger writing JSON records to buf
+func bufferLogger(buf *bytes.Buffer) httpx.Option {
+	return httpx.WithLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+func TestRequestLogValuer(t *testing.T) {
+	var logs bytes.Buffer
+	handler := httpx.Handle[loginRequest, string](func(context.Context, loginRequest) (string, error) {
+		return "ok", nil
+	}, bufferLogger(&logs))
+
+	w := post(handler, "/login", "application/json", strings.NewReader(`{"login":"bob","password":"hunter2"}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if strings.Contains(logs.String(), "hunter2") {
+		t.Errorf("logs contain password: %s", logs.String())
+	}
+	if !strings.Contains(logs.String(), `"request":{"login":"bob","password":"***"}`) {
+		t.Errorf("logs = %s, want request logged with LogValue", logs.String())
+	}
+}
+
+// groupCounter counts loggers grouped with WithGroup
+type groupCounter struct {
+	slog.Handler
+	groups *int
+}
+
+func (g groupCounter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return groupCounter{Handler: g.Handler.WithAttrs(attrs), groups: g.groups}
+}
+
+func (g groupCounter) WithGroup(name string) slog.Handler {
+	*g.groups++
+	return groupCounter{Handler: g.Handler.WithGroup(name), groups: g.groups}
+}
+
+func TestRequestLoggerCreatedOnce(t *testing.T) {
+	var (
+		logs    bytes.Buffer
+		groups  int
+		logger  = slog.New(groupCounter{Handler: slog.NewJSONHandler(&logs, nil), groups: &groups})
+		handler = httpx.Handle(func(ctx context.Context, _ emptyRequest) (string, error) {
+			httpx.LoggerFromContext(ctx).Info("use case started", slog.Int("attempt", 1))
+			return "", errorsx.New(true, http.StatusInternalServerError, "db is down")
+		}, httpx.WithLogger(logger))
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(httpx.RequestIDHeader, "req-1")
+	handler(httptest.NewRecorder(), r)
+
+	if groups != 1 {
+		t.Errorf("WithGroup called %d times, want 1", groups)
+	}
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("logs = %s, want records of use case and handler", logs.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"req-1":{`) {
+			t.Errorf("record %s is not grouped by request id", line)
+		}
+	}
+}
+
+// signupRequest fails validation of two fields
+type signupRequest struct {
+	httpx.DefaultRequest
+}
+
+func (*signupRequest) Validate() error {
+	return errorsx.NewValidationError(
+		errorsx.FieldError{Field: "email", Message: "is required"},
+		errorsx.FieldError{Field: "age", Message: "must be positive"},
+	)
+}
+
+func (signupRequest) String() string {
+	return "signup"
+}
+
+func TestValidationFailureLog(t *testing.T) {
+	var logs bytes.Buffer
+	handler := httpx.Handle(func(context.Context, signupRequest) (string, error) {
+		return "ok", nil
+	}, bufferLogger(&logs))
+
+	w := serve(handler, http.MethodPost, "/")
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	wantBody := `{"message":"validation failed","fields":[{"field":"email","message":"is required"},{"field":"age","message":"must be positive"}]}` + "\n"
+	if got := w.Body.String(); got != wantBody {
+		t.Errorf("body = %q, want %q", got, wantBody)
+	}
+	for _, want := range []string{
+		`"level":"WARN","msg":"failed to validate request"`,
+		`"err":"validation failed","fields":{"email":"is required","age":"must be positive"}`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs = %s, want %s", logs.String(), want)
+		}
+	}
+}
+
+func TestErrorLogSampling(t *testing.T) {
+	const requests = 2000
+
+	invalid := func(opts ...httpx.Option) http.HandlerFunc {
+		return httpx.Handle(func(context.Context, badValidateRequest) (string, error) {
+			return "", nil
+		}, opts...)
+	}
+
+	for name, tc := range map[string]struct {
+		rate       float64
+		handler    func(opts ...httpx.Option) http.HandlerFunc
+		logged     int
+		suppressed int
+		sampled    int
+	}{
+		"client errors sampled": {
+			rate:       0.25,
+			handler:    invalid,
+			logged:     500,
+			suppressed: 1500,
+			sampled:    requests,
+		},
+		"client errors dropped": {
+			rate:       0,
+			handler:    invalid,
+			suppressed: requests,
+			sampled:    requests,
+		},
+		"internal errors kept": {
+			rate: 0,
+			handler: func(opts ...httpx.Option) http.HandlerFunc {
+				return httpx.Handle(fail(errors.New("db is down")), opts...)
+			},
+			logged: requests,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				logs  bytes.Buffer
+				calls int
+				// cycles through 0, 0.25, 0.5 and 0.75, so a quarter of numbers is below rate of 0.25
+				random = func() float64 {
+					calls++
+					return float64(calls%4) / 4
+				}
+			)
+			handler := tc.handler(bufferLogger(&logs), httpx.WithErrorLogSampling(tc.rate), httpx.WithRandom(random))
+
+			for range requests {
+				w := serve(handler, http.MethodGet, "/")
+				if w.Code != http.StatusUnprocessableEntity && w.Code != http.StatusInternalServerError {
+					t.Fatalf("status = %d", w.Code)
+				}
+			}
+
+			got := strings.Count(logs.String(), `"level":"WARN"`) + strings.Count(logs.String(), `"level":"ERROR"`)
+			if got != tc.logged {
+				t.Errorf("logged %d errors of %d, want %d", got, requests, tc.logged)
+			}
+			if suppressed := requests - got; suppressed != tc.suppressed {
+				t.Errorf("suppressed %d errors of %d, want %d", suppressed, requests, tc.suppressed)
+			}
+			if calls != tc.sampled {
+				t.Errorf("sampled %d errors of %d, want %d", calls, requests, tc.sampled)
+			}
+		})
+	}
+}
+
+func TestLogAfterValidate(t *testing.T) {
+	invalid := func(opts ...httpx.Option) http.HandlerFunc {
+		return httpx.Handle(func(context.Context, signupRequest) (string, error) {
+			return "", nil
+		}, opts...)
+	}
+	valid := func(opts ...httpx.Option) http.HandlerFunc {
+		return httpx.Handle(reply("ok"), opts...)
+	}
+
+	for name, tc := range map[string]struct {
+		handler func(opts ...httpx.Option) http.HandlerFunc
+		after   bool
+		dropped bool
+		// want lists parts of records in order
+		want []string
+	}{
+		"before validation, invalid": {handler: invalid, want: []string{`{"request":{},"validated":false}`, `"msg":"failed to validate request"`}},
+		"before validation, valid":   {handler: valid, want: []string{`{"request":{},"validated":false}`, `"msg":"response"`}},
+		"after validation, invalid":  {handler: invalid, after: true, dropped: true, want: []string{`"msg":"failed to validate request"`}},
+		"after validation, valid":    {handler: valid, after: true, want: []string{`{"request":{}}`, `"msg":"response"`}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			serve(tc.handler(bufferLogger(&logs), httpx.WithLogAfterValidate(tc.after)), http.MethodGet, "/")
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				for _, want := range tc.want {
+					if strings.Contains(line, want) {
+						got = append(got, want)
+					}
+				}
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("logs = %s, want records %q in order", logs.String(), tc.want)
+			}
+			if logged := strings.Contains(logs.String(), `"msg":"request"`); logged == tc.dropped {
+				t.Errorf("logs = %s, request logged %v, want %v", logs.String(), logged, !tc.dropped)
+			}
+		})
+	}
+}
+
+// steppingClock returns clock advancing by step on every call
+func steppingClock(step time.Duration) func() time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestSlowThreshold(t *testing.T) {
+	tests := map[string]struct {
+		threshold time.Duration
+		duration  time.Duration
+		want      bool
+	}{
+		"above threshold": {threshold: time.Second, duration: 2 * time.Second, want: true},
+		"below threshold": {threshold: time.Second, duration: 500 * time.Millisecond, want: false},
+		"disabled":        {threshold: 0, duration: time.Hour, want: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			handler := httpx.Handle(func(context.Context, emptyRequest) (string, error) {
+				return "ok", nil
+			}, bufferLogger(&logs), httpx.WithSlowThreshold(tt.threshold), httpx.WithClock(steppingClock(tt.duration)))
+
+			serve(handler, http.MethodGet, "/items")
+
+			var warned bool
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				var record map[string]any
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("log line %q: %v", line, err)
+				}
+				if record["msg"] != "slow request" {
+					continue
+				}
+				warned = true
+				if record["level"] != "WARN" {
+					t.Errorf("slow request level = %v, want WARN", record["level"])
+				}
+			}
+			if warned != tt.want {
+				t.Errorf("slow request logged = %t, want %t, logs:\n%s", warned, tt.want, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package httpx
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Maintenance returns middleware rejecting requests with [http.StatusServiceUnavailable] and Retry-After header
+// while flag is set, in-flight requests are not affected. Requests with paths matching any of exempt predicates,
+// e.g. health probes, are always passed to the handler. Pass the same flag to ListenAndServe with WithMaintenanceFlag
+// to switch it on shutdown
+//
+// Usage:
+//
+//	var draining atomic.Bool
+//	router := httpx.NewRouter(httpx.WithMiddleware(httpx.Maintenance(&draining, 30*time.Second, func(path string) bool {
+//		return strings.HasPrefix(path, "/health")
+//	})))
+func Maintenance(flag *atomic.Bool, retryAfter time.Duration, exempt ...func(path string) bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !flag.Load() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, fn := range exempt {
+				if fn(r.URL.Path) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			}
+			writeDefaultResponse(w, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+		})
+	}
+}
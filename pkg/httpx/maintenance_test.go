@@ -0,0 +1,51 @@
+package httpx_test
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestMaintenance(t *testing.T) {
+	var (
+		draining atomic.Bool
+		calls    int
+		handler  = httpx.Maintenance(&draining, 30*time.Second, func(path string) bool {
+			return strings.HasPrefix(path, "/health")
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		}))
+	)
+
+	if w := serve(handler, http.MethodGet, "/users"); w.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("before draining status = %d, calls = %d", w.Code, calls)
+	}
+
+	draining.Store(true)
+	w := serve(handler, http.MethodGet, "/users")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want %q", got, "30")
+	}
+	if want := `{"message":"Service Unavailable"}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times while draining, want 1", calls)
+	}
+
+	if w := serve(handler, http.MethodGet, "/healthz"); w.Code != http.StatusOK || calls != 2 {
+		t.Errorf("exempt path status = %d, calls = %d", w.Code, calls)
+	}
+
+	draining.Store(false)
+	if w := serve(handler, http.MethodGet, "/users"); w.Code != http.StatusOK || calls != 3 {
+		t.Errorf("after draining status = %d, calls = %d", w.Code, calls)
+	}
+}
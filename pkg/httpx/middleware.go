@@ -0,0 +1,27 @@
+package httpx
+
+import (
+	"net/http"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+// A Middleware wraps [http.Handler] to run additional logic before and after it
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middlewares into a single one, the first middleware is the outermost
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// writeDefaultResponse writes DefaultResponse with given code from middlewares which have no configured encoder
+func writeDefaultResponse(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = encoder.JsonEncoder.New(w).Encode(DefaultResponse{Message: message})
+}
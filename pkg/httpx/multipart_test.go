@@ -0,0 +1,119 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type uploadRequest struct {
+	httpx.DefaultRequest
+	Title       string                  `form:"title,required"`
+	Avatar      *multipart.FileHeader   `file:"avatar,required"`
+	Attachments []*multipart.FileHeader `file:"attachments"`
+}
+
+func (r *uploadRequest) Bind(req *http.Request) error {
+	return httpx.BindMultipart(req, r, 1<<20)
+}
+
+func (r uploadRequest) String() string {
+	return r.Title
+}
+
+// multipartBody returns body with fields and files keyed by form name
+func multipartBody(t *testing.T, fields map[string]string, files map[string][]string) (string, *bytes.Buffer) {
+	t.Helper()
+
+	var (
+		buf    bytes.Buffer
+		writer = multipart.NewWriter(&buf)
+	)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatalf("write field: %v", err)
+		}
+	}
+	for name, contents := range files {
+		for i, content := range contents {
+			part, err := writer.CreateFormFile(name, name+string(rune('a'+i))+".txt")
+			if err != nil {
+				t.Fatalf("create file: %v", err)
+			}
+			_, _ = part.Write([]byte(content))
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close writer: %v", err)
+	}
+
+	return writer.FormDataContentType(), &buf
+}
+
+func TestBindMultipart(t *testing.T) {
+	var got uploadRequest
+	handler := httpx.HandleNoResp[uploadRequest](func(_ context.Context, req uploadRequest) error {
+		got = req
+		return nil
+	})
+
+	contentType, body := multipartBody(t,
+		map[string]string{"title": "holiday"},
+		map[string][]string{"avatar": {"face"}, "attachments": {"one", "two"}},
+	)
+	if w := post(handler, "/", contentType, body); w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d, body %q", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	if got.Title != "holiday" {
+		t.Errorf("title = %q, want %q", got.Title, "holiday")
+	}
+	if got.Avatar == nil || got.Avatar.Filename != "avatara.txt" {
+		t.Fatalf("avatar = %v, want avatara.txt", got.Avatar)
+	}
+	file, err := got.Avatar.Open()
+	if err != nil {
+		t.Fatalf("open avatar: %v", err)
+	}
+	defer file.Close()
+	if content, _ := io.ReadAll(file); string(content) != "face" {
+		t.Errorf("avatar content = %q, want %q", content, "face")
+	}
+	if len(got.Attachments) != 2 {
+		t.Errorf("attachments = %d, want 2", len(got.Attachments))
+	}
+}
+
+func TestBindMultipartErrors(t *testing.T) {
+	handler := httpx.HandleNoResp[uploadRequest](func(context.Context, uploadRequest) error {
+		return nil
+	}, httpx.WithMaxBodySize(512))
+
+	tests := map[string]struct {
+		fields map[string]string
+		files  map[string][]string
+		want   int
+	}{
+		"missing file":  {fields: map[string]string{"title": "a"}, want: http.StatusBadRequest},
+		"missing title": {files: map[string][]string{"avatar": {"face"}}, want: http.StatusBadRequest},
+		"too large":     {fields: map[string]string{"title": "a"}, files: map[string][]string{"avatar": {string(make([]byte, 1024))}}, want: http.StatusRequestEntityTooLarge},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			contentType, body := multipartBody(t, tt.fields, tt.files)
+			if w := post(handler, "/", contentType, body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d, body %q", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	if w := post(handler, "/", "multipart/form-data; boundary=x", bytes.NewBufferString("garbage")); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed body status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,210 @@
+package httpx
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses Accept header into media ranges ordered by quality, ranges with zero quality are dropped
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(accept, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: value, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	return ranges
+}
+
+// negotiate picks encoder from the registry for the Accept header, fallback encoder is preferred for wildcards
+func negotiate(registry *encoder.Registry, fallback encoder.Encoder, accept string) (encoder.Encoder, bool) {
+	var fallbackType string
+	if contentTyper, ok := fallback.(encoder.ContentTyper); ok {
+		fallbackType = mediaType(contentTyper.ContentType())
+	}
+
+	for _, r := range parseAccept(accept) {
+		switch {
+		case r.mediaType == "*/*":
+			return fallback, true
+		case strings.HasSuffix(r.mediaType, "/*"):
+			prefix := strings.TrimSuffix(r.mediaType, "*")
+			if strings.HasPrefix(fallbackType, prefix) {
+				return fallback, true
+			}
+			for _, contentType := range registry.ContentTypes() {
+				if strings.HasPrefix(contentType, prefix) {
+					if e, ok := registry.Lookup(contentType); ok {
+						return e, true
+					}
+				}
+			}
+		default:
+			if e, ok := registry.Lookup(r.mediaType); ok {
+				return e, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// formatEncoder returns encoder of the registry chosen by query parameter set with WithFormatParam.
+// Format is either a content type or its subtype, e.g. xml for application/xml. Reports whether the parameter is set,
+// nil encoder is returned for unknown formats
+func (h *handlerOptions) formatEncoder(r *http.Request) (encoder.Encoder, bool) {
+	if h.formatParam == "" {
+		return nil, false
+	}
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get(h.formatParam)))
+	if format == "" {
+		return nil, false
+	}
+
+	registry := h.registry
+	if registry == nil {
+		registry = encoder.DefaultRegistry
+	}
+
+	if e, ok := registry.Lookup(format); ok {
+		return e, true
+	}
+
+	for _, contentType := range registry.ContentTypes() {
+		if _, subtype, _ := strings.Cut(contentType, "/"); subtype == format || strings.TrimPrefix(subtype, "x-") == format {
+			if e, ok := registry.Lookup(contentType); ok {
+				return e, true
+			}
+		}
+	}
+
+	return nil, true
+}
+
+// formatHandler rejects requests with unknown format query parameter with [http.StatusBadRequest]
+func (h *handlerOptions) formatHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if e, ok := h.formatEncoder(r); ok && e == nil {
+			message := fmt.Sprintf("unknown format %q", r.URL.Query().Get(h.formatParam))
+			if err := h.writeError(w, r, http.StatusBadRequest, DefaultResponse{Message: message}); err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// encoderFor returns encoder chosen by WithEncoderFunc, then the one requested by format parameter,
+// then negotiated by Accept header of the request when registry is set, otherwise configured encoder is returned
+func (h *handlerOptions) encoderFor(r *http.Request) encoder.Encoder {
+	fallback := h.encoderFunc(r)
+	if fallback == nil {
+		fallback = encoder.JsonEncoder
+	}
+	if h.encoderSet {
+		return fallback
+	}
+
+	if e, _ := h.formatEncoder(r); e != nil {
+		return e
+	}
+
+	if h.registry == nil {
+		return fallback
+	}
+
+	if e, ok := negotiate(h.registry, fallback, strings.Join(r.Header.Values("Accept"), ",")); ok {
+		return e
+	}
+
+	return fallback
+}
+
+// acceptsCharset reports whether Accept-Charset header values allow charset, missing header allows any charset
+func acceptsCharset(values []string, charset string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	charset = strings.ToLower(charset)
+	for _, r := range parseAccept(strings.Join(values, ",")) {
+		if r.mediaType == "*" || r.mediaType == charset {
+			return true
+		}
+	}
+
+	return false
+}
+
+// charsetHandler rejects requests with [http.StatusNotAcceptable] when Accept-Charset header does not allow charset
+// of the negotiated encoder. Encoders without charset parameter in their content type, e.g. binary ones, are not checked
+func (h *handlerOptions) charsetHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := r.Header.Values("Accept-Charset")
+		if len(values) == 0 {
+			next(w, r)
+			return
+		}
+
+		var charset string
+		if contentTyper, ok := h.encoderFor(r).(encoder.ContentTyper); ok {
+			if _, params, err := mime.ParseMediaType(contentTyper.ContentType()); err == nil {
+				charset = params["charset"]
+			}
+		}
+
+		if charset != "" && !acceptsCharset(values, charset) {
+			message := fmt.Sprintf("charset %s is not acceptable", charset)
+			if err := h.writeError(w, r, http.StatusNotAcceptable, DefaultResponse{Message: message}); err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// varyHandler adds header to Vary response header, so caches keep negotiated responses apart
+func varyHandler(header string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", header)
+		next(w, r)
+	}
+}
@@ -0,0 +1,223 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type item struct {
+	Name string `json:"name" xml:"name"`
+}
+
+// typedItem declares its own content type
+type typedItem struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+func (typedItem) ContentType() string {
+	return "application/yaml"
+}
+
+func get(handler http.Handler, target, accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func itemUseCase(context.Context, emptyRequest) (item, error) {
+	return item{Name: "x"}, nil
+}
+
+func TestEncoderPrecedence(t *testing.T) {
+	vendor := encoder.WithContentType(encoder.JsonEncoder, "application/vnd.example+json")
+
+	for name, tc := range map[string]struct {
+		handler http.Handler
+		target  string
+		accept  string
+		want    string
+	}{
+		"default": {
+			handler: httpx.Handle(itemUseCase),
+			want:    "application/json; charset=utf-8",
+		},
+		"negotiation": {
+			handler: httpx.Handle(itemUseCase, httpx.WithRegistry(encoder.DefaultRegistry)),
+			accept:  "application/xml",
+			want:    "application/xml; charset=utf-8",
+		},
+		"negotiation falls back to encoder": {
+			handler: httpx.Handle(itemUseCase, httpx.WithRegistry(encoder.DefaultRegistry), httpx.WithEncoder(vendor)),
+			accept:  "text/html",
+			want:    "application/vnd.example+json",
+		},
+		"format parameter": {
+			handler: httpx.Handle(itemUseCase, httpx.WithFormatParam("format")),
+			target:  "/?format=xml",
+			want:    "application/xml; charset=utf-8",
+		},
+		"encoder func beats negotiation": {
+			handler: httpx.Handle(itemUseCase, httpx.WithRegistry(encoder.DefaultRegistry),
+				httpx.WithEncoderFunc(func(*http.Request) encoder.Encoder { return vendor })),
+			accept: "application/xml",
+			want:   "application/vnd.example+json",
+		},
+		"encoder func beats format parameter": {
+			handler: httpx.Handle(itemUseCase, httpx.WithFormatParam("format"),
+				httpx.WithEncoderFunc(func(*http.Request) encoder.Encoder { return vendor })),
+			target: "/?format=xml",
+			want:   "application/vnd.example+json",
+		},
+		"response content type beats negotiation": {
+			handler: httpx.Handle(func(context.Context, emptyRequest) (typedItem, error) {
+				return typedItem{Name: "x"}, nil
+			}, httpx.WithRegistry(encoder.DefaultRegistry)),
+			accept: "application/xml",
+			want:   "application/yaml; charset=utf-8",
+		},
+		"encoder func beats response content type": {
+			handler: httpx.Handle(func(context.Context, emptyRequest) (typedItem, error) {
+				return typedItem{Name: "x"}, nil
+			}, httpx.WithEncoderFunc(func(*http.Request) encoder.Encoder { return encoder.JsonEncoder })),
+			want: "application/json; charset=utf-8",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			target := tc.target
+			if target == "" {
+				target = "/"
+			}
+			w := get(tc.handler, target, tc.accept)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %q", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); got != tc.want {
+				t.Errorf("Content-Type = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncoderFunc(t *testing.T) {
+	handler := httpx.Handle(itemUseCase, httpx.WithEncoderFunc(func(r *http.Request) encoder.Encoder {
+		switch r.Header.Get("X-Debug") {
+		case "pretty":
+			return encoder.NewJSONEncoder(encoder.WithIndent("  "))
+		case "xml":
+			return encoder.XmlEncoder
+		}
+		return nil
+	}))
+
+	for name, tc := range map[string]struct {
+		debug       string
+		contentType string
+		body        string
+	}{
+		"pretty":         {debug: "pretty", contentType: "application/json; charset=utf-8", body: "{\n  \"name\": \"x\"\n}\n"},
+		"xml":            {debug: "xml", contentType: "application/xml; charset=utf-8", body: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<item><name>x</name></item>"},
+		"nil falls back": {contentType: "application/json; charset=utf-8", body: "{\"name\":\"x\"}\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.debug != "" {
+				r.Header.Set("X-Debug", tc.debug)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tc.contentType)
+			}
+			if got := w.Body.String(); got != tc.body {
+				t.Errorf("body = %q, want %q", got, tc.body)
+			}
+		})
+	}
+}
+
+func TestFormatParam(t *testing.T) {
+	handler := httpx.Handle(itemUseCase, httpx.WithFormatParam("format"), httpx.WithRegistry(encoder.DefaultRegistry))
+
+	tests := map[string]struct {
+		target     string
+		accept     string
+		wantStatus int
+		want       string
+	}{
+		"subtype":            {target: "/?format=yaml", wantStatus: http.StatusOK, want: "application/yaml; charset=utf-8"},
+		"content type":       {target: "/?format=application/xml", wantStatus: http.StatusOK, want: "application/xml; charset=utf-8"},
+		"overrides accept":   {target: "/?format=xml", accept: "application/yaml", wantStatus: http.StatusOK, want: "application/xml; charset=utf-8"},
+		"absent uses accept": {target: "/", accept: "application/yaml", wantStatus: http.StatusOK, want: "application/yaml; charset=utf-8"},
+		"unknown format":     {target: "/?format=pdf", wantStatus: http.StatusBadRequest, want: "application/json; charset=utf-8"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := get(handler, tt.target, tt.accept)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %q", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.want {
+				t.Errorf("Content-Type = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceptCharset(t *testing.T) {
+	for name, tc := range map[string]struct {
+		handler       http.Handler
+		acceptCharset string
+		code          int
+	}{
+		"missing":        {handler: httpx.Handle(itemUseCase), code: http.StatusOK},
+		"utf-8":          {handler: httpx.Handle(itemUseCase), acceptCharset: "utf-8", code: http.StatusOK},
+		"upper case":     {handler: httpx.Handle(itemUseCase), acceptCharset: "UTF-8", code: http.StatusOK},
+		"any":            {handler: httpx.Handle(itemUseCase), acceptCharset: "*", code: http.StatusOK},
+		"utf-8 among":    {handler: httpx.Handle(itemUseCase), acceptCharset: "iso-8859-1, utf-8;q=0.5", code: http.StatusOK},
+		"iso-8859-1":     {handler: httpx.Handle(itemUseCase), acceptCharset: "iso-8859-1", code: http.StatusNotAcceptable},
+		"binary encoder": {handler: httpx.Handle(itemUseCase, httpx.WithEncoder(encoder.MsgpackEncoder)), acceptCharset: "iso-8859-1", code: http.StatusOK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptCharset != "" {
+				r.Header.Set("Accept-Charset", tc.acceptCharset)
+			}
+			w := httptest.NewRecorder()
+			tc.handler.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Errorf("status = %d, want %d, body %q", w.Code, tc.code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestContentTypeParameters(t *testing.T) {
+	for name, contentType := range map[string]string{
+		"vendor":     "application/vnd.myapp.v2+json",
+		"parameters": `application/json; schema="https://example.com/user.v2"`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := httpx.Handle(itemUseCase, httpx.WithEncoder(encoder.WithContentType(encoder.JsonEncoder, contentType)))
+
+			w := get(handler, "/", "")
+			if got := w.Header().Get("Content-Type"); got != contentType {
+				t.Errorf("Content-Type = %q, want %q", got, contentType)
+			}
+			if got := w.Body.String(); got != `{"name":"x"}`+"\n" {
+				t.Errorf("body = %q", got)
+			}
+		})
+	}
+}
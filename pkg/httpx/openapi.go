@@ -0,0 +1,311 @@
+package httpx
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// operation describes route registered with a use case, it is used to generate OpenAPI document
+type operation struct {
+	method   string
+	path     string
+	request  reflect.Type
+	response reflect.Type
+	code     int
+
+	summary     string
+	description string
+	tags        []string
+}
+
+// WithSummary sets summary of the route in OpenAPI document. It does not change handler behavior
+// and is used only for routes registered on Router
+func WithSummary(summary string) Option {
+	return func(h *handlerOptions) {
+		h.summary = summary
+	}
+}
+
+// WithDescription sets description of the route in OpenAPI document. It does not change handler behavior
+// and is used only for routes registered on Router
+func WithDescription(description string) Option {
+	return func(h *handlerOptions) {
+		h.description = description
+	}
+}
+
+// WithTags adds tags to the route in OpenAPI document, tags set on router defaults are kept.
+// It does not change handler behavior and is used only for routes registered on Router
+//
+// Usage:
+//
+//	users := router.Group("/users").With(httpx.WithTags("users"))
+//	httpx.Get(users, "/{id}", userUseCase.Get, httpx.WithSummary("Get user by id"))
+func WithTags(tags ...string) Option {
+	return func(h *handlerOptions) {
+		h.tags = append(slices.Clone(h.tags), tags...)
+	}
+}
+
+// OpenAPI generates minimal OpenAPI 3.0 JSON document from routes registered with Get, Post and other package
+// functions on the router and its groups. Parameters are derived from path, query and header tags of the request,
+// request and response schemas from json tags. Fields with `validate:"required"` tag are listed as required.
+// Routes registered with Handle are not included, their types are unknown
+//
+// Usage:
+//
+//	spec, err := router.OpenAPI()
+//	if err != nil {
+//		return err
+//	}
+//	router.Mux().HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+//		w.Header().Set("Content-Type", "application/json")
+//		_, _ = w.Write(spec)
+//	})
+func (r *Router) OpenAPI() ([]byte, error) {
+	r.routes.mu.RLock()
+	operations := slices.Clone(r.routes.operations)
+	r.routes.mu.RUnlock()
+
+	paths := make(map[string]map[string]any)
+	for _, op := range operations {
+		path := openAPIPath(op.path)
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		paths[path][strings.ToLower(op.method)] = op.spec()
+	}
+
+	return json.Marshal(map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+	})
+}
+
+// openAPIPath converts [http.ServeMux] pattern into OpenAPI path template
+func openAPIPath(path string) string {
+	path = strings.TrimSuffix(path, "{$}")
+	return strings.ReplaceAll(path, "...}", "}")
+}
+
+func (op operation) spec() map[string]any {
+	var (
+		spec       = make(map[string]any)
+		path       = openAPIPath(op.path)
+		parameters []any
+	)
+
+	if op.summary != "" {
+		spec["summary"] = op.summary
+	}
+	if op.description != "" {
+		spec["description"] = op.description
+	}
+	if len(op.tags) > 0 {
+		spec["tags"] = op.tags
+	}
+
+	for _, parameter := range openAPIParameters(op.request) {
+		if p := parameter.(map[string]any); p["in"] == pathTag && !strings.Contains(path, "{"+p["name"].(string)+"}") {
+			continue
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	if len(parameters) > 0 {
+		spec["parameters"] = parameters
+	}
+
+	if op.method == http.MethodPost || op.method == http.MethodPut || op.method == http.MethodPatch {
+		if schema := openAPISchema(op.request, map[reflect.Type]bool{}); len(schema) > 0 && schema["properties"] != nil {
+			spec["requestBody"] = map[string]any{
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schema},
+				},
+			}
+		}
+	}
+
+	response := map[string]any{"description": http.StatusText(op.code)}
+	if op.code != http.StatusNoContent {
+		response["content"] = map[string]any{
+			"application/json": map[string]any{"schema": openAPISchema(responseType(op.response), map[reflect.Type]bool{})},
+		}
+	}
+	spec["responses"] = map[string]any{strconv.Itoa(op.code): response}
+
+	return spec
+}
+
+// responseType returns type of the value encoded for response type t, Result is unwrapped
+func responseType(t reflect.Type) reflect.Type {
+	if t.Implements(reflect.TypeFor[resultValuer]()) && t.Kind() == reflect.Struct {
+		if field, ok := t.FieldByName("Value"); ok {
+			return field.Type
+		}
+	}
+	return t
+}
+
+// openAPIParameters returns path, query and header parameters of request type t
+func openAPIParameters(t reflect.Type) []any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var parameters []any
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && !hasParameterTag(field) {
+			parameters = append(parameters, openAPIParameters(field.Type)...)
+			continue
+		}
+
+		for _, in := range []string{pathTag, queryTag, headerTag} {
+			options, ok := parseTag(field.Tag.Get(in))
+			if !ok {
+				continue
+			}
+
+			schema := openAPISchema(field.Type, map[reflect.Type]bool{})
+			if len(options.oneof) > 0 {
+				schema["enum"] = options.oneof
+			}
+
+			parameters = append(parameters, map[string]any{
+				"name":     options.name,
+				"in":       in,
+				"required": in == pathTag || options.required,
+				"schema":   schema,
+			})
+		}
+	}
+
+	return parameters
+}
+
+func hasParameterTag(field reflect.StructField) bool {
+	for _, tag := range []string{pathTag, queryTag, headerTag, formTag, fileTag} {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPISchema returns JSON schema of type t, recursive types are described as free-form objects
+func openAPISchema(t reflect.Type, seen map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == reflect.TypeFor[time.Duration]():
+		return map[string]any{"type": "string"}
+	case t.Implements(reflect.TypeFor[json.Marshaler]()) || reflect.PointerTo(t).Implements(reflect.TypeFor[json.Marshaler]()):
+		return map[string]any{}
+	case t.Implements(reflect.TypeFor[encoding.TextMarshaler]()) || reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextMarshaler]()):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": openAPISchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": openAPISchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]any{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		properties := make(map[string]any)
+		var required []string
+		openAPIProperties(t, seen, properties, &required)
+
+		schema := map[string]any{"type": "object"}
+		if len(properties) > 0 {
+			schema["properties"] = properties
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
+
+// openAPIProperties collects properties of struct t named by json tags, embedded structs without a tag are flattened.
+// Fields bound from path, query, header or form are skipped unless they have json tag
+func openAPIProperties(t reflect.Type, seen map[reflect.Type]bool, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, hasJSON := field.Tag.Lookup(jsonTag)
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if !hasJSON && hasParameterTag(field) {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				openAPIProperties(embedded, seen, properties, required)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = openAPISchema(field.Type, seen)
+		if slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package httpx_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type searchRequest struct {
+	httpx.DefaultRequest
+	ID      int64         `path:"id"`
+	Status  string        `query:"status,required,oneof=active inactive"`
+	From    time.Time     `query:"from"`
+	Timeout time.Duration `query:"timeout"`
+}
+
+func (searchRequest) String() string {
+	return "search"
+}
+
+func TestOpenAPIParameters(t *testing.T) {
+	router := httpx.NewRouter()
+	httpx.Get(router, "/items/{id}", func(context.Context, searchRequest) (string, error) {
+		return "", nil
+	}, httpx.WithSummary("Search items"))
+
+	data, err := router.OpenAPI()
+	if err != nil {
+		t.Fatalf("OpenAPI() error = %v", err)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Summary    string `json:"summary"`
+			Parameters []struct {
+				Name     string         `json:"name"`
+				In       string         `json:"in"`
+				Required bool           `json:"required"`
+				Schema   map[string]any `json:"schema"`
+			} `json:"parameters"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("unmarshal spec: %v", err)
+	}
+
+	op, ok := spec.Paths["/items/{id}"]["get"]
+	if !ok {
+		t.Fatalf("spec has no GET /items/{id}: %s", data)
+	}
+	if op.Summary != "Search items" {
+		t.Errorf("summary = %q, want %q", op.Summary, "Search items")
+	}
+
+	want := map[string]map[string]any{
+		"id":      {"type": "integer", "format": "int64"},
+		"status":  {"type": "string", "enum": []any{"active", "inactive"}},
+		"from":    {"type": "string", "format": "date-time"},
+		"timeout": {"type": "string"},
+	}
+	if len(op.Parameters) != len(want) {
+		t.Fatalf("parameters = %d, want %d", len(op.Parameters), len(want))
+	}
+	for _, p := range op.Parameters {
+		if !reflect.DeepEqual(p.Schema, want[p.Name]) {
+			t.Errorf("%s schema = %v, want %v", p.Name, p.Schema, want[p.Name])
+		}
+		if wantRequired := p.Name == "id" || p.Name == "status"; p.Required != wantRequired {
+			t.Errorf("%s required = %v, want %v", p.Name, p.Required, wantRequired)
+		}
+	}
+}
+
+func TestOpenAPIAnnotations(t *testing.T) {
+	annotations := []httpx.Option{
+		httpx.WithSummary("Get user"),
+		httpx.WithDescription("Returns user by id"),
+		httpx.WithTags("users"),
+		httpx.WithTags("admin"),
+	}
+
+	router := httpx.NewRouter()
+	httpx.Get(router, "/users/{id}", func(_ context.Context, req pathRequest) (string, error) {
+		return req.ID, nil
+	}, annotations...)
+	httpx.Get(router, "/health", func(context.Context, emptyRequest) (string, error) {
+		return "ok", nil
+	})
+
+	data, err := router.OpenAPI()
+	if err != nil {
+		t.Fatalf("OpenAPI() error = %v", err)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Summary     string   `json:"summary"`
+			Description string   `json:"description"`
+			Tags        []string `json:"tags"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("unmarshal spec: %v", err)
+	}
+
+	op := spec.Paths["/users/{id}"]["get"]
+	if op.Summary != "Get user" || op.Description != "Returns user by id" {
+		t.Errorf("summary = %q, description = %q", op.Summary, op.Description)
+	}
+	if !reflect.DeepEqual(op.Tags, []string{"users", "admin"}) {
+		t.Errorf("tags = %v, want [users admin]", op.Tags)
+	}
+	if health := spec.Paths["/health"]["get"]; health.Summary != "" || len(health.Tags) != 0 {
+		t.Errorf("unannotated route has summary %q and tags %v", health.Summary, health.Tags)
+	}
+
+	if w := serve(router, http.MethodGet, "/users/7"); w.Code != http.StatusOK || w.Body.String() != `"7"`+"\n" {
+		t.Errorf("status = %d, body %q", w.Code, w.Body.String())
+	}
+	if w := serve(httpx.Handle(reply("ok"), annotations...), http.MethodGet, "/"); w.Code != http.StatusOK || w.Body.String() != `"ok"`+"\n" {
+		t.Errorf("plain Handle status = %d, body %q", w.Code, w.Body.String())
+	}
+}
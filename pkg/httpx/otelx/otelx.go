@@ -0,0 +1,87 @@
+// Package otelx provides OpenTelemetry tracing for httpx handlers
+package otelx
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// WithTracing starts OpenTelemetry server span named after the method and matched route around the handler.
+// Status code and [errorsx.Errorx] code are recorded as span attributes, 5xx responses mark the span as errored.
+// Trace id of the span is used as request id in logs
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, otelx.WithTracing(otel.Tracer("api")))
+func WithTracing(tracer trace.Tracer) httpx.Option {
+	return httpx.WithOptions(
+		httpx.WithOuterMiddleware(Middleware(tracer)),
+		httpx.WithRequestIDFunc(traceID),
+		httpx.WithErrorObserver(recordError),
+	)
+}
+
+// Middleware starts server span around next handler, incoming trace context is extracted
+// with the global OpenTelemetry propagator
+func Middleware(tracer trace.Tracer) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				ctx   = otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+				route = httpx.RouteOf(r)
+				name  = r.Method
+			)
+
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			rec := httpx.NewResponseRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			status := rec.Status()
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
+
+// traceID returns trace id of the span in request context, empty for requests without span
+func traceID(r *http.Request) string {
+	if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	return ""
+}
+
+// recordError records err in the span of the request
+func recordError(r *http.Request, err error) {
+	span := trace.SpanFromContext(r.Context())
+	if errx, ok := errorsx.As(err); ok {
+		span.SetAttributes(
+			attribute.Int("error.code", errx.Code()),
+			attribute.Bool("error.internal", errx.Internal()),
+		)
+	}
+	span.RecordError(err)
+}
@@ -0,0 +1,151 @@
+package otelx_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+	"github.com/abdivasiyev/rester/pkg/httpx/otelx"
+)
+
+// recordingTracer keeps spans it started, spans share span context of the parent
+type recordingTracer struct {
+	embedded.Tracer
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	noop.Span
+	name   string
+	sc     trace.SpanContext
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	errors []error
+	ended  bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordingSpan{
+		name:  name,
+		sc:    trace.SpanContextFromContext(ctx),
+		attrs: make(map[attribute.Key]attribute.Value),
+	}
+	config := trace.NewSpanStartConfig(options...)
+	span.SetAttributes(config.Attributes()...)
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *recordingSpan) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *recordingSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+
+func (s *recordingSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.errors = append(s.errors, err)
+}
+
+func (s *recordingSpan) End(...trace.SpanEndOption) { s.ended = true }
+
+type emptyRequest struct {
+	httpx.DefaultRequest
+}
+
+func (emptyRequest) String() string {
+	return "empty"
+}
+
+func TestWithTracing(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantCode   codes.Code
+	}{
+		"success":        {wantStatus: http.StatusOK, wantCode: codes.Unset},
+		"client error":   {err: errorsx.New(false, http.StatusNotFound, "not found"), wantStatus: http.StatusNotFound, wantCode: codes.Unset},
+		"internal error": {err: errorsx.New(true, http.StatusInternalServerError, "db is down"), wantStatus: http.StatusInternalServerError, wantCode: codes.Error},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				tracer    = &recordingTracer{}
+				requestID string
+				mux       = http.NewServeMux()
+			)
+
+			mux.HandleFunc("GET /items/{id}", httpx.Handle[emptyRequest, string](func(ctx context.Context, _ emptyRequest) (string, error) {
+				requestID, _ = httpx.RequestIDFromContext(ctx)
+				return "ok", tt.err
+			},
+				httpx.WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))),
+				otelx.WithTracing(tracer),
+			))
+
+			r := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+			r.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if requestID != traceID {
+				t.Errorf("request id = %q, want trace id %q", requestID, traceID)
+			}
+			if len(tracer.spans) != 1 {
+				t.Fatalf("spans = %d, want 1", len(tracer.spans))
+			}
+
+			span := tracer.spans[0]
+			if span.name != "GET /items/{id}" {
+				t.Errorf("span name = %q, want %q", span.name, "GET /items/{id}")
+			}
+			if !span.ended {
+				t.Error("span is not ended")
+			}
+			if got := span.attrs["http.response.status_code"].AsInt64(); got != int64(tt.wantStatus) {
+				t.Errorf("status attribute = %d, want %d", got, tt.wantStatus)
+			}
+			if span.status != tt.wantCode {
+				t.Errorf("span status = %v, want %v", span.status, tt.wantCode)
+			}
+			if (tt.err != nil) != (len(span.errors) == 1) {
+				t.Errorf("recorded errors = %v, want %v", span.errors, tt.err)
+			}
+			if tt.err != nil {
+				if got := span.attrs["error.code"].AsInt64(); got != int64(tt.wantStatus) {
+					t.Errorf("error.code = %d, want %d", got, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package httpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+)
+
+const (
+	// DefaultPerPage is a page size used by BindPagination when per_page query parameter is absent
+	DefaultPerPage = 20
+	// MaxPerPage is a maximum page size accepted by BindPagination, larger values are capped
+	MaxPerPage = 100
+)
+
+// A Page is an envelope of list responses. Page numbers start from 1
+//
+// Usage:
+//
+//	func (u *useCase) List(ctx context.Context, req ListRequest) (httpx.Page[User], error) {
+//		users, total, err := u.repo.List(ctx, req.Pagination.Offset(), req.Pagination.PerPage)
+//		if err != nil {
+//			return httpx.Page[User]{}, err
+//		}
+//		return httpx.NewPage(users, req.Pagination.Page, req.Pagination.PerPage, total).WithLinks(req.URL), nil
+//	}
+type Page[T any] struct {
+	XMLName    xml.Name `json:"-" xml:"page" msgpack:"-" yaml:"-"`
+	Items      []T      `json:"items" xml:"items>item" msgpack:"items" yaml:"items"`
+	Page       int      `json:"page" xml:"page" msgpack:"page" yaml:"page"`
+	PerPage    int      `json:"per_page" xml:"per_page" msgpack:"per_page" yaml:"per_page"`
+	Total      int      `json:"total" xml:"total" msgpack:"total" yaml:"total"`
+	TotalPages int      `json:"total_pages" xml:"total_pages" msgpack:"total_pages" yaml:"total_pages"`
+
+	header http.Header
+}
+
+// NewPage creates Page of items computing total number of pages. Nil items are encoded as an empty list
+func NewPage[T any](items []T, page, perPage, total int) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	var totalPages int
+	if perPage > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+
+	return Page[T]{
+		Items:      items,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// WithLinks returns copy of the page with RFC 5988 Link header pointing to first, prev, next and last pages of u.
+// Page and per_page query parameters of u are replaced, others are kept
+func (p Page[T]) WithLinks(u *url.URL) Page[T] {
+	link := p.Link(u)
+	if link == "" {
+		return p
+	}
+
+	p.header = http.Header{"Link": {link}}
+	return p
+}
+
+// Link returns value of RFC 5988 Link header pointing to first, prev, next and last pages of u
+func (p Page[T]) Link(u *url.URL) string {
+	if u == nil || p.TotalPages == 0 {
+		return ""
+	}
+
+	var links []string
+	add := func(page int, rel string) {
+		query := u.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(p.PerPage))
+
+		link := *u
+		link.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", link.String(), rel))
+	}
+
+	add(1, "first")
+	if p.Page > 1 {
+		add(min(p.Page-1, p.TotalPages), "prev")
+	}
+	if p.Page < p.TotalPages {
+		add(p.Page+1, "next")
+	}
+	add(p.TotalPages, "last")
+
+	return strings.Join(links, ", ")
+}
+
+func (p Page[T]) Header() http.Header {
+	return p.header
+}
+
+// A Pagination is a page requested by client
+type Pagination struct {
+	Page    int
+	PerPage int
+}
+
+// Offset returns number of items before the page
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// BindPagination parses page and per_page query parameters of [http.Request]. Page defaults to 1,
+// per_page defaults to DefaultPerPage and is capped by MaxPerPage.
+// Returns [http.StatusBadRequest] error when parameters are not positive integers
+func BindPagination(r *http.Request) (Pagination, error) {
+	var (
+		lookup     = lookupValues(r, r.URL.Query())
+		pagination = Pagination{Page: 1, PerPage: DefaultPerPage}
+	)
+
+	params := [...]struct {
+		key string
+		dst *int
+	}{
+		{key: "page", dst: &pagination.Page},
+		{key: "per_page", dst: &pagination.PerPage},
+	}
+
+	for _, param := range params {
+		key, dst := param.key, param.dst
+		values := lookup(key)
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		value := values[0]
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return Pagination{}, errorsx.New(false, http.StatusBadRequest, fmt.Sprintf("invalid query %q: positive integer is expected", key))
+		}
+		*dst = n
+	}
+
+	pagination.PerPage = min(pagination.PerPage, MaxPerPage)
+
+	return pagination, nil
+}
@@ -0,0 +1,101 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type listRequest struct {
+	httpx.DefaultRequest
+	Pagination httpx.Pagination
+	request    *http.Request
+}
+
+func (r *listRequest) Bind(req *http.Request) (err error) {
+	r.request = req
+	r.Pagination, err = httpx.BindPagination(req)
+	return err
+}
+
+func (r listRequest) String() string {
+	return "list"
+}
+
+func TestPage(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+
+	handler := httpx.Handle[listRequest, httpx.Page[string]](func(_ context.Context, req listRequest) (httpx.Page[string], error) {
+		var (
+			start = min(req.Pagination.Offset(), len(names))
+			end   = min(start+req.Pagination.PerPage, len(names))
+		)
+		return httpx.NewPage(names[start:end], req.Pagination.Page, req.Pagination.PerPage, len(names)).WithLinks(req.request.URL), nil
+	})
+
+	w := serve(handler, http.MethodGet, "/users?page=2&per_page=2&sort=name")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if want := `{"items":["c","d"],"page":2,"per_page":2,"total":5,"total_pages":3}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+
+	wantLink := `</users?page=1&per_page=2&sort=name>; rel="first", ` +
+		`</users?page=1&per_page=2&sort=name>; rel="prev", ` +
+		`</users?page=3&per_page=2&sort=name>; rel="next", ` +
+		`</users?page=3&per_page=2&sort=name>; rel="last"`
+	if got := w.Header().Get("Link"); got != wantLink {
+		t.Errorf("Link = %q, want %q", got, wantLink)
+	}
+}
+
+func TestNewPageEmpty(t *testing.T) {
+	page := httpx.NewPage[string](nil, 1, 20, 0)
+	if page.Items == nil || page.TotalPages != 0 {
+		t.Errorf("page = %+v, want empty items and no pages", page)
+	}
+	if link := page.Link(httptest.NewRequest(http.MethodGet, "/users", nil).URL); link != "" {
+		t.Errorf("Link() = %q, want empty", link)
+	}
+}
+
+func TestBindPagination(t *testing.T) {
+	tests := map[string]struct {
+		target  string
+		want    httpx.Pagination
+		wantErr bool
+	}{
+		"defaults":      {target: "/", want: httpx.Pagination{Page: 1, PerPage: httpx.DefaultPerPage}},
+		"explicit":      {target: "/?page=3&per_page=10", want: httpx.Pagination{Page: 3, PerPage: 10}},
+		"capped":        {target: "/?per_page=1000", want: httpx.Pagination{Page: 1, PerPage: httpx.MaxPerPage}},
+		"zero page":     {target: "/?page=0", wantErr: true},
+		"not a number":  {target: "/?per_page=ten", wantErr: true},
+		"empty ignored": {target: "/?page=", want: httpx.Pagination{Page: 1, PerPage: httpx.DefaultPerPage}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := httpx.BindPagination(httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BindPagination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BindPagination() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindPaginationErrorOrder(t *testing.T) {
+	for range 20 {
+		_, err := httpx.BindPagination(httptest.NewRequest(http.MethodGet, "/?page=0&per_page=x", nil))
+		if want := `invalid query "page": positive integer is expected`; err == nil || err.Error() != want {
+			t.Fatalf("BindPagination() error = %v, want %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package promx provides Prometheus metrics for httpx handlers
+package promx
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type metricsOptions struct {
+	namespace string
+	subsystem string
+	buckets   []float64
+}
+
+// An Option is a type to set optional parameters to Metrics middleware
+type Option func(m *metricsOptions)
+
+// WithNamespace sets namespace of collected metrics
+func WithNamespace(namespace string) Option {
+	return func(m *metricsOptions) {
+		m.namespace = namespace
+	}
+}
+
+// WithSubsystem sets subsystem of collected metrics
+func WithSubsystem(subsystem string) Option {
+	return func(m *metricsOptions) {
+		m.subsystem = subsystem
+	}
+}
+
+// WithBuckets sets buckets of request duration histogram. Default value is a [prometheus.DefBuckets]
+func WithBuckets(buckets ...float64) Option {
+	return func(m *metricsOptions) {
+		m.buckets = buckets
+	}
+}
+
+// Metrics returns middleware recording http_requests_total counter and http_request_duration_seconds histogram
+// labeled by method, matched route pattern and status code. Collectors already registered in reg are reused,
+// so the middleware can be created for every handler
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithMiddleware(promx.Metrics(prometheus.DefaultRegisterer)))
+func Metrics(reg prometheus.Registerer, options ...Option) httpx.Middleware {
+	var m metricsOptions
+	for _, option := range options {
+		option(&m)
+	}
+
+	if len(m.buckets) == 0 {
+		m.buckets = prometheus.DefBuckets
+	}
+
+	labels := []string{"method", "path", "code"}
+
+	requests := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace,
+		Subsystem: m.subsystem,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests.",
+	}, labels))
+
+	duration := registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace,
+		Subsystem: m.subsystem,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests in seconds.",
+		Buckets:   m.buckets,
+	}, labels))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				start = time.Now()
+				rec   = httpx.NewResponseRecorder(w)
+			)
+
+			next.ServeHTTP(rec, r)
+
+			path := httpx.RouteOf(r)
+			if path == "" {
+				path = "unmatched"
+			}
+
+			values := []string{r.Method, path, strconv.Itoa(rec.Status())}
+			requests.WithLabelValues(values...).Inc()
+			duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// registerCollector registers collector in reg, returning the existing one when it was already registered.
+// Panics on other registration errors as [prometheus.MustRegister] does
+func registerCollector[C prometheus.Collector](reg prometheus.Registerer, collector C) C {
+	if err := reg.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+
+	return collector
+}
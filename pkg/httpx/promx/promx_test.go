@@ -0,0 +1,74 @@
+package promx_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/abdivasiyev/rester/pkg/httpx/promx"
+)
+
+// counterValue returns value of http_requests_total series with labels
+func counterValue(t *testing.T, reg *prometheus.Registry, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "api_http_requests_total" {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mux := http.NewServeMux()
+
+	// middleware is created per handler, collectors registered by the first one are reused
+	mux.Handle("GET /items/{id}", promx.Metrics(reg, promx.WithNamespace("api"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	mux.Handle("GET /fail", promx.Metrics(reg, promx.WithNamespace("api"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})))
+	unmatched := promx.Metrics(reg, promx.WithNamespace("api"))(http.NotFoundHandler())
+
+	for _, target := range []string{"/items/1", "/items/2", "/fail"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	unmatched.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	tests := map[string]struct {
+		labels map[string]string
+		want   float64
+	}{
+		"route":     {labels: map[string]string{"method": "GET", "path": "/items/{id}", "code": "204"}, want: 2},
+		"failure":   {labels: map[string]string{"method": "GET", "path": "/fail", "code": "500"}, want: 1},
+		"unmatched": {labels: map[string]string{"method": "GET", "path": "unmatched", "code": "404"}, want: 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := counterValue(t, reg, tt.labels); got != tt.want {
+				t.Errorf("http_requests_total%v = %v, want %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
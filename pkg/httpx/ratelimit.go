@@ -0,0 +1,123 @@
+package httpx
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// rateLimitMaxKeys is a maximum number of limiters kept by RateLimit middleware
+	rateLimitMaxKeys = 10_000
+	// rateLimitIdleTimeout is a duration after which limiter of inactive key is evicted
+	rateLimitIdleTimeout = 10 * time.Minute
+)
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// limiterStore keeps per key limiters, evicting idle keys and the least recently seen key when it is full
+type limiterStore struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	maxKeys   int
+	idle      time.Duration
+	lastSweep time.Time
+	limiters  map[string]*limiterEntry
+}
+
+func newLimiterStore(limit rate.Limit, burst int) *limiterStore {
+	return &limiterStore{
+		limit:    limit,
+		burst:    burst,
+		maxKeys:  rateLimitMaxKeys,
+		idle:     rateLimitIdleTimeout,
+		limiters: make(map[string]*limiterEntry),
+	}
+}
+
+func (s *limiterStore) get(key string, now time.Time) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > s.idle {
+		s.lastSweep = now
+		for k, entry := range s.limiters {
+			if now.Sub(entry.lastSeen) > s.idle {
+				delete(s.limiters, k)
+			}
+		}
+	}
+
+	if entry, ok := s.limiters[key]; ok {
+		entry.lastSeen = now
+		return entry.limiter
+	}
+
+	if len(s.limiters) >= s.maxKeys {
+		var (
+			oldestKey  string
+			oldestSeen time.Time
+		)
+		for k, entry := range s.limiters {
+			if oldestKey == "" || entry.lastSeen.Before(oldestSeen) {
+				oldestKey, oldestSeen = k, entry.lastSeen
+			}
+		}
+		delete(s.limiters, oldestKey)
+	}
+
+	limiter := rate.NewLimiter(s.limit, s.burst)
+	s.limiters[key] = &limiterEntry{limiter: limiter, lastSeen: now}
+
+	return limiter
+}
+
+// RateLimit returns middleware limiting requests per key with token bucket of given rate and burst.
+// Requests exceeding the limit are rejected with [http.StatusTooManyRequests] and Retry-After header.
+// When keyFunc is nil, requests are limited by client IP address
+//
+// Usage:
+//
+//	httpx.Handle[Request, Response](useCase, httpx.WithMiddleware(httpx.RateLimit(rate.Every(time.Second), 10, nil)))
+func RateLimit(r rate.Limit, burst int, keyFunc func(*http.Request) string) Middleware {
+	if keyFunc == nil {
+		keyFunc = remoteIP
+	}
+
+	store := newLimiterStore(r, burst)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			now := time.Now()
+
+			reservation := store.get(keyFunc(req), now).ReserveN(now, 1)
+			if delay := reservation.DelayFrom(now); !reservation.OK() || delay > 0 {
+				reservation.CancelAt(now)
+				if reservation.OK() {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				}
+				writeDefaultResponse(w, http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests))
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,75 @@
+package httpx_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestRateLimit(t *testing.T) {
+	handler := httpx.Handle[emptyRequest, string](reply("ok"),
+		httpx.WithMiddleware(httpx.RateLimit(rate.Every(time.Hour), 2, nil)),
+	)
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	for i := range 2 {
+		if w := request("10.0.0.1:1000"); w.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d", i, w.Code, http.StatusOK)
+		}
+	}
+
+	w := request("10.0.0.1:2000")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "3600" {
+		t.Errorf("Retry-After = %q, want %q", got, "3600")
+	}
+
+	if w := request("10.0.0.2:1000"); w.Code != http.StatusOK {
+		t.Errorf("other client status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitKeyFunc(t *testing.T) {
+	handler := httpx.Handle[emptyRequest, string](reply("ok"),
+		httpx.WithMiddleware(httpx.RateLimit(rate.Every(time.Hour), 1, func(r *http.Request) string {
+			return r.Header.Get("X-API-Key")
+		})),
+	)
+
+	request := func(key string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "a", want: http.StatusOK},
+		{key: "a", want: http.StatusTooManyRequests},
+		{key: "b", want: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		if got := request(tt.key); got != tt.want {
+			t.Errorf("request %d with key %q status = %d, want %d", i, tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+)
+
+// A Raw is a pre-encoded response, e.g. cached JSON, which is written as is bypassing configured encoder.
+// Empty ContentType is sent as application/octet-stream. Only content type and size of the body are logged
+//
+// Usage:
+//
+//	func (u *useCase) Get(ctx context.Context, req Request) (httpx.Raw, error) {
+//		blob, err := u.cache.Get(ctx, req.ID)
+//		if err != nil {
+//			return httpx.Raw{}, err
+//		}
+//		return httpx.Raw{ContentType: "application/json", Body: blob}, nil
+//	}
+type Raw struct {
+	ContentType string
+	Body        []byte
+}
+
+func (r Raw) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("content_type", r.ContentType),
+		slog.Int("size", len(r.Body)),
+	)
+}
+
+// rawEncoder writes body of Raw responses
+type rawEncoder struct {
+	w           io.Writer
+	contentType string
+}
+
+func newRawEncoder(raw Raw) encoder.Encoder {
+	contentType := raw.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &rawEncoder{contentType: contentType}
+}
+
+func (e *rawEncoder) New(w io.Writer) encoder.Encoder {
+	return &rawEncoder{w: w, contentType: e.contentType}
+}
+
+func (e *rawEncoder) Encode(src any) error {
+	raw, ok := src.(Raw)
+	if !ok {
+		return fmt.Errorf("raw: cannot encode %T", src)
+	}
+
+	_, err := e.w.Write(raw.Body)
+	return err
+}
+
+func (e *rawEncoder) ContentType() string {
+	return e.contentType
+}
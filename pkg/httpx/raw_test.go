@@ -0,0 +1,62 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestRaw(t *testing.T) {
+	for name, tc := range map[string]struct {
+		raw         httpx.Raw
+		options     []httpx.Option
+		contentType string
+	}{
+		"text": {
+			raw:         httpx.Raw{ContentType: "text/plain", Body: []byte("  cached\n")},
+			contentType: "text/plain",
+		},
+		"default content type": {
+			raw:         httpx.Raw{Body: []byte{0, 1, 2}},
+			contentType: "application/octet-stream",
+		},
+		"bypasses encoder": {
+			raw:         httpx.Raw{ContentType: "application/json", Body: []byte(`{"b":1,"a":2}`)},
+			options:     []httpx.Option{httpx.WithEncoder(encoder.XmlEncoder)},
+			contentType: "application/json",
+		},
+		"bypasses negotiation": {
+			raw:         httpx.Raw{ContentType: "text/plain", Body: []byte("cached")},
+			options:     []httpx.Option{httpx.WithRegistry(encoder.DefaultRegistry)},
+			contentType: "text/plain",
+		},
+		"bypasses response wrapper": {
+			raw: httpx.Raw{ContentType: "text/plain", Body: []byte("cached")},
+			options: []httpx.Option{httpx.WithResponseWrapper(func(_ context.Context, response any) (any, error) {
+				return map[string]any{"data": response}, nil
+			})},
+			contentType: "text/plain",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := httpx.Handle(func(context.Context, emptyRequest) (httpx.Raw, error) {
+				return tc.raw, nil
+			}, tc.options...)
+
+			w := get(handler, "/", "application/xml")
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tc.contentType)
+			}
+			if got := w.Body.String(); got != string(tc.raw.Body) {
+				t.Errorf("body = %q, want %q", got, tc.raw.Body)
+			}
+		})
+	}
+}
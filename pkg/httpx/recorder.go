@@ -0,0 +1,104 @@
+package httpx
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// A ResponseRecorder wraps [http.ResponseWriter] recording status code and number of bytes written to it,
+// for middlewares which observe responses like metrics and access logs. Flush and Hijack are passed through
+// to the wrapped writer with [http.ResponseController], Hijack returns [http.ErrNotSupported] when the wrapped writer
+// does not support it. Repeated WriteHeader calls are dropped
+//
+// Usage:
+//
+//	rec := httpx.NewResponseRecorder(w)
+//	next.ServeHTTP(rec, r)
+//	log.Println(rec.Status(), rec.BytesWritten())
+type ResponseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// NewResponseRecorder creates ResponseRecorder wrapping w
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w}
+}
+
+// WriteHeader records and writes code once, informational 1xx codes are passed through without being recorded
+func (s *ResponseRecorder) WriteHeader(code int) {
+	if s.wroteHeader {
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		s.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	s.status = code
+	s.wroteHeader = true
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *ResponseRecorder) Write(b []byte) (int, error) {
+	if !s.wroteHeader {
+		s.WriteHeader(http.StatusOK)
+	}
+
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += int64(n)
+	return n, err
+}
+
+func (s *ResponseRecorder) Flush() {
+	if !s.wroteHeader {
+		s.status = http.StatusOK
+		s.wroteHeader = true
+	}
+	_ = http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+func (s *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(s.ResponseWriter).Hijack()
+	if err == nil && !s.wroteHeader {
+		s.status = http.StatusSwitchingProtocols
+		s.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+func (s *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Status returns written status code, [http.StatusOK] is returned when nothing was written
+func (s *ResponseRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
+// BytesWritten returns number of body bytes written
+func (s *ResponseRecorder) BytesWritten() int64 {
+	return s.bytes
+}
+
+// recordStatus sets code as status of every ResponseRecorder in the chain of writers wrapped by w which has no status yet,
+// so logs and metrics see it. Nothing is sent to the client
+func recordStatus(w http.ResponseWriter, code int) {
+	for w != nil {
+		if rec, ok := w.(*ResponseRecorder); ok && !rec.wroteHeader {
+			rec.status = code
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = unwrapper.Unwrap()
+	}
+}
@@ -0,0 +1,55 @@
+package httpx_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// headerCounter counts WriteHeader calls reaching the wrapped writer
+type headerCounter struct {
+	*httptest.ResponseRecorder
+	calls int
+}
+
+func (c *headerCounter) WriteHeader(code int) {
+	c.calls++
+	c.ResponseRecorder.WriteHeader(code)
+}
+
+func TestResponseRecorder(t *testing.T) {
+	var (
+		w   = &headerCounter{ResponseRecorder: httptest.NewRecorder()}
+		rec = httpx.NewResponseRecorder(w)
+	)
+
+	rec.WriteHeader(http.StatusCreated)
+	rec.WriteHeader(http.StatusInternalServerError)
+	_, _ = rec.Write([]byte("hello"))
+	rec.Flush()
+
+	if rec.Status() != http.StatusCreated || w.Code != http.StatusCreated {
+		t.Errorf("status = %d, sent %d, want %d", rec.Status(), w.Code, http.StatusCreated)
+	}
+	if w.calls != 1 {
+		t.Errorf("WriteHeader reached wrapped writer %d times, want 1", w.calls)
+	}
+	if rec.BytesWritten() != 5 {
+		t.Errorf("bytes written = %d, want 5", rec.BytesWritten())
+	}
+	if !w.Flushed {
+		t.Error("Flush was not passed through")
+	}
+}
+
+func TestResponseRecorderHijackNotSupported(t *testing.T) {
+	rec := httpx.NewResponseRecorder(httptest.NewRecorder())
+	if _, _, err := rec.Hijack(); err == nil {
+		t.Error("Hijack succeeded on writer without hijacking support")
+	}
+	if rec.Status() != http.StatusOK {
+		t.Errorf("status = %d after failed hijack", rec.Status())
+	}
+}
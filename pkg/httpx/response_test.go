@@ -0,0 +1,233 @@
+package httpx_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestHeadRequest(t *testing.T) {
+	handler := httpx.Handle[emptyRequest, string](reply("hello"))
+
+	get := serve(handler, http.MethodGet, "/")
+	head := serve(handler, http.MethodHead, "/")
+
+	if head.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", head.Code, http.StatusOK)
+	}
+	if head.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", head.Body.String())
+	}
+	if got, want := head.Header().Get("Content-Length"), strconv.Itoa(get.Body.Len()); got != want {
+		t.Errorf("Content-Length = %q, want %q", got, want)
+	}
+	if got, want := head.Header().Get("Content-Type"), get.Header().Get("Content-Type"); got != want {
+		t.Errorf("Content-Type = %q, want %q", got, want)
+	}
+}
+
+func TestContentLength(t *testing.T) {
+	tests := map[string]struct {
+		options        []httpx.Option
+		acceptEncoding string
+		want           string
+	}{
+		"buffered body":       {want: strconv.Itoa(len("\"hello\"\n"))},
+		"gzip not accepted":   {options: []httpx.Option{httpx.WithGzip(gzip.BestSpeed)}, want: strconv.Itoa(len("\"hello\"\n"))},
+		"compressed response": {options: []httpx.Option{httpx.WithGzip(gzip.BestSpeed)}, acceptEncoding: "gzip"},
+		"no content":          {options: []httpx.Option{httpx.WithSuccessCode(http.StatusNoContent)}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := httpx.Handle[emptyRequest, string](reply("hello"), tt.options...)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if got := w.Header().Get("Content-Length"); got != tt.want {
+				t.Errorf("Content-Length = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && strconv.Itoa(w.Body.Len()) != tt.want {
+				t.Errorf("body length = %d, want %s", w.Body.Len(), tt.want)
+			}
+		})
+	}
+}
+
+// failingWriter fails every write with err
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	err error
+}
+
+func (f failingWriter) Write([]byte) (int, error) {
+	return 0, f.err
+}
+
+func TestWriteToClosedConnection(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err   error
+		level string
+		msg   string
+	}{
+		"closed connection": {err: net.ErrClosed, level: "DEBUG", msg: "client closed connection"},
+		"broken pipe":       {err: &net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)}, level: "DEBUG", msg: "client closed connection"},
+		"connection reset":  {err: fmt.Errorf("write: %w", syscall.ECONNRESET), level: "DEBUG", msg: "client closed connection"},
+		"other error":       {err: errors.New("disk is full"), level: "ERROR", msg: "failed to write response"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			handler := httpx.Handle[emptyRequest, string](reply("hello"), bufferLogger(&logs))
+
+			handler(failingWriter{ResponseRecorder: httptest.NewRecorder(), err: tc.err}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			want := fmt.Sprintf(`"level":%q,"msg":%q`, tc.level, tc.msg)
+			if !strings.Contains(logs.String(), want) {
+				t.Errorf("logs = %s, want %s", logs.String(), want)
+			}
+			if tc.level == "DEBUG" && strings.Contains(logs.String(), `"level":"ERROR"`) {
+				t.Errorf("logs = %s, want no errors", logs.String())
+			}
+		})
+	}
+}
+
+type envelope struct {
+	Data any `json:"data"`
+	Meta struct {
+		RequestID string `json:"request_id"`
+	} `json:"meta"`
+}
+
+func TestResponseWrapper(t *testing.T) {
+	wrap := httpx.WithResponseWrapper(func(ctx context.Context, response any) (any, error) {
+		var e envelope
+		e.Data = response
+		e.Meta.RequestID, _ = httpx.RequestIDFromContext(ctx)
+		return e, nil
+	})
+
+	for name, tc := range map[string]struct {
+		handler http.HandlerFunc
+		code    int
+		body    string
+	}{
+		"response": {
+			handler: httpx.Handle(itemUseCase, wrap),
+			code:    http.StatusOK,
+			body:    `{"data":{"name":"x"},"meta":{"request_id":"req-1"}}` + "\n",
+		},
+		"result value": {
+			handler: httpx.HandleResult(func(context.Context, emptyRequest) (httpx.Result[item], error) {
+				return httpx.Result[item]{Value: item{Name: "x"}, Code: http.StatusCreated}, nil
+			}, wrap),
+			code: http.StatusCreated,
+			body: `{"data":{"name":"x"},"meta":{"request_id":"req-1"}}` + "\n",
+		},
+		"use case error is not wrapped": {
+			handler: httpx.Handle(fail(errNotFound), wrap),
+			code:    http.StatusNotFound,
+			body:    `"not found"` + "\n",
+		},
+		"wrapper error": {
+			handler: httpx.Handle(itemUseCase, httpx.WithResponseWrapper(func(context.Context, any) (any, error) {
+				return nil, errNotFound
+			})),
+			code: http.StatusNotFound,
+			body: `"not found"` + "\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(httpx.RequestIDHeader, "req-1")
+			w := httptest.NewRecorder()
+			tc.handler(w, r)
+
+			if w.Code != tc.code {
+				t.Errorf("status = %d, want %d", w.Code, tc.code)
+			}
+			if got := w.Body.String(); got != tc.body {
+				t.Errorf("body = %q, want %q", got, tc.body)
+			}
+		})
+	}
+}
+
+func TestPreferReturnMinimal(t *testing.T) {
+	create := func(context.Context, emptyRequest) (httpx.Result[item], error) {
+		return httpx.Result[item]{
+			Value:   item{Name: "x"},
+			Code:    http.StatusCreated,
+			Headers: http.Header{"Location": {"/items/1"}},
+		}, nil
+	}
+
+	for name, tc := range map[string]struct {
+		options []httpx.Option
+		prefer  string
+		applied string
+		body    string
+	}{
+		"minimal":        {options: []httpx.Option{httpx.WithPreferHandling()}, prefer: "return=minimal", applied: "return=minimal"},
+		"minimal listed": {options: []httpx.Option{httpx.WithPreferHandling()}, prefer: "respond-async, return = minimal; foo", applied: "return=minimal"},
+		"representation": {options: []httpx.Option{httpx.WithPreferHandling()}, prefer: "return=representation", body: `{"name":"x"}` + "\n"},
+		"absent":         {options: []httpx.Option{httpx.WithPreferHandling()}, body: `{"name":"x"}` + "\n"},
+		"disabled":       {prefer: "return=minimal", body: `{"name":"x"}` + "\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/items", nil)
+			if tc.prefer != "" {
+				r.Header.Set("Prefer", tc.prefer)
+			}
+			w := httptest.NewRecorder()
+			httpx.HandleResult(create, tc.options...)(w, r)
+
+			if w.Code != http.StatusCreated {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+			}
+			if got := w.Header().Get("Location"); got != "/items/1" {
+				t.Errorf("Location = %q", got)
+			}
+			if got := w.Header().Get("Preference-Applied"); got != tc.applied {
+				t.Errorf("Preference-Applied = %q, want %q", got, tc.applied)
+			}
+			if got := w.Body.String(); got != tc.body {
+				t.Errorf("body = %q, want %q", got, tc.body)
+			}
+		})
+	}
+}
+
+func TestNoopEncoder(t *testing.T) {
+	handler := httpx.Handle(reply("hello"), httpx.WithEncoder(encoder.Noop), httpx.WithSuccessCode(http.StatusAccepted))
+
+	w := serve(handler, http.MethodPost, "/")
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+	if got, ok := w.Header()["Content-Type"]; ok {
+		t.Errorf("Content-Type = %q, want none", got)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+}
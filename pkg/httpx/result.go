@@ -0,0 +1,56 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+)
+
+// A StatusCoder is implemented by responses which choose their own success code instead of the one
+// set by WithSuccessCode. Non-positive codes are ignored
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// A Result wraps use case response with status code and headers, so domain structures stay free of HTTP concerns.
+// Handle encodes only the Value
+//
+// Usage:
+//
+//	func (u *useCase) Save(ctx context.Context, req Request) (httpx.Result[User], error) {
+//		user, created, err := u.repo.Upsert(ctx, req.User)
+//		if err != nil {
+//			return httpx.Result[User]{}, err
+//		}
+//		if created {
+//			return httpx.Result[User]{Value: user, Code: http.StatusCreated}, nil
+//		}
+//		return httpx.Result[User]{Value: user}, nil
+//	}
+type Result[T any] struct {
+	Value   T
+	Code    int
+	Headers http.Header
+}
+
+func (r Result[T]) StatusCode() int {
+	return r.Code
+}
+
+func (r Result[T]) Header() http.Header {
+	return r.Headers
+}
+
+func (r Result[T]) resultValue() any {
+	return r.Value
+}
+
+// resultValuer is implemented by Result to unwrap the encoded value
+type resultValuer interface {
+	resultValue() any
+}
+
+// HandleResult is a variant of Handle for use cases returning Result, status code and headers of the result
+// are applied to the response and its value is encoded
+func HandleResult[Req any, Resp any, _Req Request[Req]](useCase func(context.Context, Req) (Result[Resp], error), options ...Option) http.HandlerFunc {
+	return Handle[Req, Result[Resp], _Req](useCase, options...)
+}
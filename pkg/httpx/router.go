@@ -0,0 +1,245 @@
+package httpx
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A Router is a thin wrapper around [http.ServeMux] which registers use cases with default options
+// applied to every route. Per-route options are applied after the default ones, so they win.
+//
+// Go methods can not have type parameters, so routes are registered with package level functions:
+//
+//	router := httpx.NewRouter(httpx.WithLogger(logger), httpx.WithEncoder(encoder.JsonEncoder))
+//	httpx.Get(router, "/users/{id}", userUseCase.Get)
+//	httpx.Post(router, "/users", userUseCase.Create, httpx.WithSuccessCode(http.StatusCreated))
+//	http.ListenAndServe(":8080", router)
+//
+// OPTIONS requests to a registered path are answered with [http.StatusNoContent] and Allow header listing
+// methods registered for the path, requests with other methods are rejected with [http.StatusMethodNotAllowed].
+// Requests to unknown paths are rejected with [http.StatusNotFound], both with DefaultResponse body
+type Router struct {
+	mux         *http.ServeMux
+	routes      *routes
+	prefix      string
+	options     []Option
+	middlewares []Middleware
+}
+
+// NewRouter creates Router with default options applied to every registered route
+func NewRouter(options ...Option) *Router {
+	r := &Router{
+		mux:     http.NewServeMux(),
+		routes:  &routes{paths: map[string]*route{"/": {}}, fallbacks: http.NewServeMux()},
+		options: options,
+	}
+
+	r.routes.fallbacks.Handle("/", r.routes.fallback("/", nil))
+
+	return r
+}
+
+// Mux returns underlying [http.ServeMux]
+func (r *Router) Mux() *http.ServeMux {
+	return r.mux
+}
+
+// ServeHTTP dispatches request to the registered route, requests matching no pattern of the mux
+// are answered by the fallback of their path
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if _, pattern := r.mux.Handler(req); pattern == "" {
+		r.routes.fallbacks.ServeHTTP(w, req)
+		return
+	}
+	r.mux.ServeHTTP(w, req)
+}
+
+// Group returns sub-router registering routes on the same mux with prefix prepended to their paths and middlewares
+// applied to them. Default options and middlewares of the router are inherited
+//
+// Usage:
+//
+//	api := router.Group("/api/v1", authMiddleware)
+//	httpx.Get(api, "/users", userUseCase.List) // GET /api/v1/users
+func (r *Router) Group(prefix string, middlewares ...Middleware) *Router {
+	return &Router{
+		mux:         r.mux,
+		routes:      r.routes,
+		prefix:      joinPath(r.prefix, prefix),
+		options:     append([]Option(nil), r.options...),
+		middlewares: append(append([]Middleware(nil), r.middlewares...), middlewares...),
+	}
+}
+
+// With returns router registering routes on the same mux with options appended to default options of r,
+// r is not modified. Use it to set defaults like timeout or body size limit for a group of routes,
+// per-route options still override them
+//
+// Usage:
+//
+//	router := httpx.NewRouter(httpx.WithTimeout(30*time.Second), httpx.WithMaxBodySize(1<<20))
+//	uploads := router.Group("/uploads").With(httpx.WithTimeout(5*time.Minute), httpx.WithMaxBodySize(100<<20))
+//	httpx.Post(uploads, "/", uploadUseCase.Create)
+//	httpx.Get(router, "/report", reportUseCase.Get, httpx.WithTimeout(time.Minute))
+func (r *Router) With(options ...Option) *Router {
+	return &Router{
+		mux:         r.mux,
+		routes:      r.routes,
+		prefix:      r.prefix,
+		options:     r.withDefaults(options),
+		middlewares: append([]Middleware(nil), r.middlewares...),
+	}
+}
+
+// Handle registers handler for method and path. Empty method matches any method
+func (r *Router) Handle(method, path string, handler http.Handler) {
+	pattern := joinPath(r.prefix, path)
+
+	if len(r.middlewares) > 0 {
+		handler = Chain(r.middlewares...)(handler)
+	}
+
+	if r.routes.add(pattern, method, handler) {
+		r.routes.fallbacks.Handle(pattern, r.routes.fallback(pattern, r.middlewares))
+	}
+
+	if method != "" {
+		r.mux.Handle(method+" "+pattern, handler)
+	}
+}
+
+// routes tracks methods registered for every path of the router and its groups. Fallbacks of the paths are kept
+// in a separate mux, method-less patterns conflict with method patterns like "GET /" in one mux
+type routes struct {
+	mu         sync.RWMutex
+	paths      map[string]*route
+	fallbacks  *http.ServeMux
+	operations []operation
+}
+
+type route struct {
+	methods []string
+	any     http.Handler
+}
+
+// add records method of the path, handler is kept for empty method. Reports whether the path is new
+func (rt *routes) add(path, method string, handler http.Handler) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	r, ok := rt.paths[path]
+	if !ok {
+		r = &route{}
+		rt.paths[path] = r
+	}
+
+	if method == "" {
+		r.any = handler
+	} else {
+		r.methods = append(r.methods, method)
+	}
+
+	return !ok
+}
+
+// fallback returns handler serving requests to the path which matched no registered method.
+// OPTIONS, 404 and 405 responses pass through middlewares of the group which registered the path first,
+// so e.g. CORS middleware answers preflight requests. Root path without methods catches requests to unknown paths
+func (rt *routes) fallback(path string, middlewares []Middleware) http.Handler {
+	var respond http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rt.mu.RLock()
+		allow := strings.Join(rt.paths[path].methods, ", ")
+		rt.mu.RUnlock()
+
+		if allow == "" {
+			writeDefaultResponse(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+			return
+		}
+
+		w.Header().Set("Allow", allow)
+		if req.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeDefaultResponse(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+	})
+	if len(middlewares) > 0 {
+		respond = Chain(middlewares...)(respond)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rt.mu.RLock()
+		handler := rt.paths[path].any
+		rt.mu.RUnlock()
+
+		if handler != nil {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		respond.ServeHTTP(w, req)
+	})
+}
+
+// joinPath joins prefix and path with a single slash, keeping trailing slash of the path
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" {
+		return prefix
+	}
+
+	return strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// withDefaults returns default options of the router followed by options
+func (r *Router) withDefaults(options []Option) []Option {
+	return append(append(make([]Option, 0, len(r.options)+len(options)), r.options...), options...)
+}
+
+func handleRoute[Req any, Resp any, _Req Request[Req]](router *Router, method, path string, useCase UseCaseFunc[Req, Resp], options []Option) {
+	h := applyOptions(router.withDefaults(options)...)
+	router.Handle(method, path, handle[Req, Resp, _Req](h, useCase))
+
+	router.routes.mu.Lock()
+	router.routes.operations = append(router.routes.operations, operation{
+		method:      method,
+		path:        joinPath(router.prefix, path),
+		request:     reflect.TypeFor[Req](),
+		response:    reflect.TypeFor[Resp](),
+		code:        h.successCode,
+		summary:     h.summary,
+		description: h.description,
+		tags:        h.tags,
+	})
+	router.routes.mu.Unlock()
+}
+
+// Get registers use case for GET requests on path
+func Get[Req any, Resp any, _Req Request[Req]](router *Router, path string, useCase UseCaseFunc[Req, Resp], options ...Option) {
+	handleRoute[Req, Resp, _Req](router, http.MethodGet, path, useCase, options)
+}
+
+// Post registers use case for POST requests on path
+func Post[Req any, Resp any, _Req Request[Req]](router *Router, path string, useCase UseCaseFunc[Req, Resp], options ...Option) {
+	handleRoute[Req, Resp, _Req](router, http.MethodPost, path, useCase, options)
+}
+
+// Put registers use case for PUT requests on path
+func Put[Req any, Resp any, _Req Request[Req]](router *Router, path string, useCase UseCaseFunc[Req, Resp], options ...Option) {
+	handleRoute[Req, Resp, _Req](router, http.MethodPut, path, useCase, options)
+}
+
+// Patch registers use case for PATCH requests on path
+func Patch[Req any, Resp any, _Req Request[Req]](router *Router, path string, useCase UseCaseFunc[Req, Resp], options ...Option) {
+	handleRoute[Req, Resp, _Req](router, http.MethodPatch, path, useCase, options)
+}
+
+// Delete registers use case for DELETE requests on path
+func Delete[Req any, Resp any, _Req Request[Req]](router *Router, path string, useCase UseCaseFunc[Req, Resp], options ...Option) {
+	handleRoute[Req, Resp, _Req](router, http.MethodDelete, path, useCase, options)
+}
@@ -0,0 +1,161 @@
+package httpx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestRouterRootRoute(t *testing.T) {
+	for name, register := range map[string]func(*httpx.Router){
+		"root first": func(r *httpx.Router) {
+			httpx.Get(r, "/", reply("root"))
+			httpx.Get(r, "/users", reply("users"))
+		},
+		"root last": func(r *httpx.Router) {
+			httpx.Get(r, "/users", reply("users"))
+			httpx.Get(r, "/", reply("root"))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			router := httpx.NewRouter()
+			register(router)
+
+			if w := serve(router, http.MethodGet, "/users"); w.Code != http.StatusOK || w.Body.String() != "\"users\"\n" {
+				t.Errorf("GET /users = %d %q", w.Code, w.Body.String())
+			}
+			if w := serve(router, http.MethodGet, "/"); w.Code != http.StatusOK || w.Body.String() != "\"root\"\n" {
+				t.Errorf("GET / = %d %q", w.Code, w.Body.String())
+			}
+			if w := serve(router, http.MethodPost, "/users"); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET" {
+				t.Errorf("POST /users = %d, Allow %q", w.Code, w.Header().Get("Allow"))
+			}
+			if w := serve(router, http.MethodOptions, "/users"); w.Code != http.StatusNoContent || w.Header().Get("Allow") != "GET" {
+				t.Errorf("OPTIONS /users = %d, Allow %q", w.Code, w.Header().Get("Allow"))
+			}
+		})
+	}
+}
+
+func TestRouterGroupMiddlewaresOnFallback(t *testing.T) {
+	var (
+		router = httpx.NewRouter()
+		api    = router.Group("/api", func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Group", "api")
+				next.ServeHTTP(w, r)
+			})
+		})
+	)
+	httpx.Get(api, "/users", reply("users"))
+
+	for _, method := range []string{http.MethodGet, http.MethodOptions, http.MethodDelete} {
+		if w := serve(router, method, "/api/users"); w.Header().Get("X-Group") != "api" {
+			t.Errorf("%s /api/users: group middleware was not applied, status %d", method, w.Code)
+		}
+	}
+}
+
+// tag returns middleware appending name to X-Chain header, so tests see which middlewares ran and in which order
+func tag(name string) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Chain", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRouterGroups(t *testing.T) {
+	var (
+		router = httpx.NewRouter()
+		api    = router.Group("/api", tag("api"))
+		v1     = api.Group("v1/", tag("v1"))
+		admin  = router.Group("/admin", tag("admin"))
+	)
+	httpx.Get(v1, "/users/{id}", func(ctx context.Context, req pathRequest) (string, error) {
+		return "user " + req.ID, nil
+	})
+	httpx.Get(admin, "/stats", reply("stats"))
+	httpx.Get(router, "/health", reply("ok"))
+
+	tests := map[string]struct {
+		target string
+		body   string
+		chain  []string
+	}{
+		"nested group": {target: "/api/v1/users/7", body: "\"user 7\"\n", chain: []string{"api", "v1"}},
+		"sibling":      {target: "/admin/stats", body: "\"stats\"\n", chain: []string{"admin"}},
+		"root":         {target: "/health", body: "\"ok\"\n"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target)
+			if w.Code != http.StatusOK || w.Body.String() != tt.body {
+				t.Fatalf("GET %s = %d %q, want %q", tt.target, w.Code, w.Body.String(), tt.body)
+			}
+			if got := w.Header().Values("X-Chain"); !slices.Equal(got, tt.chain) {
+				t.Errorf("middlewares = %v, want %v", got, tt.chain)
+			}
+		})
+	}
+}
+
+func TestRouterWithDefaults(t *testing.T) {
+	var (
+		router  = httpx.NewRouter(httpx.WithSuccessCode(http.StatusAccepted))
+		created = router.Group("/items").With(httpx.WithSuccessCode(http.StatusCreated))
+	)
+	httpx.Post(created, "/", reply("created"))
+	httpx.Post(created, "/ok", reply("ok"), httpx.WithSuccessCode(http.StatusOK))
+	httpx.Post(router, "/jobs", reply("queued"))
+
+	tests := map[string]int{
+		"/items/":   http.StatusCreated,
+		"/items/ok": http.StatusOK,
+		"/jobs":     http.StatusAccepted,
+	}
+
+	for target, want := range tests {
+		if w := serve(router, http.MethodPost, target); w.Code != want {
+			t.Errorf("POST %s = %d, want %d", target, w.Code, want)
+		}
+	}
+}
+
+func TestHandlerInfoFromContext(t *testing.T) {
+	var (
+		info  httpx.HandlerInfo
+		found bool
+	)
+	inspect := httpx.WithMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info, found = httpx.HandlerInfoFromContext(r.Context())
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	router := httpx.NewRouter()
+	httpx.Post(router, "/items/{id}", func(_ context.Context, req pathRequest) (string, error) {
+		return req.ID, nil
+	}, inspect, httpx.WithSuccessCode(http.StatusCreated), httpx.WithRegistry(encoder.DefaultRegistry))
+
+	r := httptest.NewRequest(http.MethodPost, "/items/1", nil)
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	want := httpx.HandlerInfo{SuccessCode: http.StatusCreated, ContentType: "application/xml; charset=utf-8", Route: "POST /items/{id}"}
+	if !found || info != want {
+		t.Errorf("HandlerInfoFromContext() = %+v, %v, want %+v", info, found, want)
+	}
+	if _, ok := httpx.HandlerInfoFromContext(context.Background()); ok {
+		t.Error("HandlerInfoFromContext() found info in background context")
+	}
+}
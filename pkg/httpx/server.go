@@ -0,0 +1,130 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/slogx"
+)
+
+// defaultGracePeriod is a time given to in-flight requests to finish on shutdown
+const defaultGracePeriod = 10 * time.Second
+
+type serverOptions struct {
+	gracePeriod time.Duration
+	logger      *slog.Logger
+	maintenance *atomic.Bool
+}
+
+// A ServerOption is a type to set optional parameters to ListenAndServe
+type ServerOption func(s *serverOptions)
+
+// WithGracePeriod sets time given to in-flight requests to finish on shutdown. Default value is 10 seconds
+func WithGracePeriod(d time.Duration) ServerOption {
+	return func(s *serverOptions) {
+		s.gracePeriod = d
+	}
+}
+
+// WithServerLogger sets custom slog instance for startup and shutdown messages. Default value is generated from slogx.New()
+func WithServerLogger(logger *slog.Logger) ServerOption {
+	return func(s *serverOptions) {
+		s.logger = logger
+	}
+}
+
+// WithMaintenanceFlag sets flag when shutdown starts, so Maintenance middleware rejects requests
+// arriving on open connections while in-flight requests finish
+func WithMaintenanceFlag(flag *atomic.Bool) ServerOption {
+	return func(s *serverOptions) {
+		s.maintenance = flag
+	}
+}
+
+// ListenAndServe serves handler on addr until ctx is cancelled or SIGINT/SIGTERM is received, then shuts the server down
+// gracefully waiting for in-flight requests until the grace period passes, remaining connections are closed afterwards.
+// Returns an error if the server fails to bind, stops unexpectedly or does not shut down in time.
+// Logger created with slogx.WithAsync is flushed before return, waiting no longer than the grace period
+//
+// Usage:
+//
+//	if err := httpx.ListenAndServe(ctx, ":8080", mux, httpx.WithServerLogger(logger)); err != nil {
+//		logger.Error("server failed", slog.Any("err", err))
+//	}
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler, options ...ServerOption) error {
+	var s serverOptions
+	for _, option := range options {
+		option(&s)
+	}
+
+	if s.gracePeriod <= 0 {
+		s.gracePeriod = defaultGracePeriod
+	}
+
+	if s.logger == nil {
+		s.logger = slogx.New()
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), s.gracePeriod)
+		defer cancel()
+		_ = slogx.Flush(flushCtx, s.logger)
+	}()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Handler: handler,
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithoutCancel(ctx)
+		},
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	s.logger.Info("server started", slog.String("addr", listener.Addr().String()))
+
+	select {
+	case err = <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("shutting down server", slog.Duration("grace_period", s.gracePeriod))
+
+	if s.maintenance != nil {
+		s.maintenance.Store(true)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.gracePeriod)
+	defer cancel()
+
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		s.logger.Error("graceful shutdown failed, closing connections", slog.Any("err", err))
+		_ = server.Close()
+		return err
+	}
+
+	if err = <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	s.logger.Info("server stopped")
+
+	return nil
+}
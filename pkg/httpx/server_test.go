@@ -0,0 +1,119 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// freeAddr returns local address with a free port
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// waitListening waits until addr accepts connections
+func waitListening(t *testing.T, addr string) {
+	t.Helper()
+	for range 100 {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s is not listening", addr)
+}
+
+func TestListenAndServeClosesAfterGracePeriod(t *testing.T) {
+	addr := freeAddr(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- httpx.ListenAndServe(ctx, addr, handler,
+			httpx.WithGracePeriod(50*time.Millisecond),
+			httpx.WithServerLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))),
+		)
+	}()
+
+	waitListening(t, addr)
+
+	clientErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr)
+		if err == nil {
+			resp.Body.Close()
+		}
+		clientErr <- err
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("ListenAndServe() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListenAndServe() did not return after grace period")
+	}
+
+	select {
+	case err := <-clientErr:
+		if err == nil {
+			t.Error("in-flight request succeeded, want connection closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("in-flight request was not closed")
+	}
+}
+
+func TestListenAndServeSetsMaintenanceFlag(t *testing.T) {
+	var (
+		addr        = freeAddr(t)
+		draining    atomic.Bool
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan error, 1)
+	)
+	go func() {
+		done <- httpx.ListenAndServe(ctx, addr, http.NotFoundHandler(),
+			httpx.WithMaintenanceFlag(&draining),
+			httpx.WithServerLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))),
+		)
+	}()
+
+	waitListening(t, addr)
+	if draining.Load() {
+		t.Fatal("flag is set before shutdown")
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("ListenAndServe() error = %v", err)
+	}
+	if !draining.Load() {
+		t.Error("flag is not set on shutdown")
+	}
+}
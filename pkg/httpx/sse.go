@@ -0,0 +1,137 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// An SSEEvent is a single Server-Sent Event. Empty Event and ID fields are omitted,
+// multiline Data is written as multiple data lines. Event and ID with line breaks are rejected by send
+// with ErrSSELineBreak, they would inject extra fields into the stream
+type SSEEvent struct {
+	Event string
+	Data  string
+	ID    string
+}
+
+// SSEFunc is a type to implement streaming business logic functions. Every event passed to send is written
+// and flushed to the client immediately
+type SSEFunc[Req any] func(ctx context.Context, req Req, send func(event SSEEvent) error) error
+
+// HandleSSE is a variant of Handle for Server-Sent Events endpoints. Request is bound and validated before the stream
+// starts, so errors are returned with a normal status. Stream starts with the first sent event and ends when the use case
+// returns or request context is cancelled. Errors returned after the stream started are sent as "error" event
+// with StreamError message, see WithStreamErrorHandler to change it
+//
+// Usage:
+//
+//	mux.HandleFunc("GET /events", httpx.HandleSSE[Request](func(ctx context.Context, req Request, send func(httpx.SSEEvent) error) error {
+//		return send(httpx.SSEEvent{Event: "tick", Data: "1"})
+//	}))
+func HandleSSE[Req any, _Req Request[Req]](useCase SSEFunc[Req], options ...Option) http.HandlerFunc {
+	var h = applyOptions(options...)
+
+	return h.wrap(func(w http.ResponseWriter, r *http.Request) {
+		var logger = h.loggerFor(r)
+
+		req, ok := bind[Req, _Req](&h, w, r, logger)
+		if !ok {
+			return
+		}
+
+		if !canFlush(w) {
+			logger.ErrorContext(r.Context(), "response writer does not support flushing")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w = NewContextWriter(r.Context(), w)
+		flusher := w.(http.Flusher)
+
+		var (
+			ctx     = r.Context()
+			started bool
+		)
+
+		start := func() {
+			if started {
+				return
+			}
+			started = true
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(h.successCode)
+			flusher.Flush()
+		}
+
+		send := func(event SSEEvent) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Event, "\r\n") {
+				return ErrSSELineBreak
+			}
+
+			start()
+			if err := writeSSEEvent(w, event); err != nil {
+				return err
+			}
+			flusher.Flush()
+
+			return nil
+		}
+
+		err := useCase(ctx, req, send)
+		if err != nil {
+			if !started {
+				h.writeUseCaseError(w, r, err)
+				return
+			}
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			logger.ErrorContext(r.Context(), "failed to stream events", slog.Any("err", err))
+			if h.streamErrorHandler != nil {
+				h.streamErrorHandler(w, r, err)
+				return
+			}
+			if err = writeSSEEvent(w, SSEEvent{Event: "error", Data: NewStreamError(err).Message}); err != nil {
+				logger.ErrorContext(r.Context(), "failed to write stream error", slog.Any("err", err))
+				return
+			}
+			flusher.Flush()
+			return
+		}
+
+		start()
+	})
+}
+
+// ErrSSELineBreak is returned by send of HandleSSE for events with line breaks in Event or ID
+var ErrSSELineBreak = errors.New("httpx: SSE event name and id must not contain line breaks")
+
+// sseLineBreaks splits event data into lines, CR, LF and CRLF all end a line in the event stream
+var sseLineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func writeSSEEvent(w io.Writer, event SSEEvent) error {
+	var b strings.Builder
+
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event.Event)
+	}
+	for _, line := range strings.Split(sseLineBreaks.Replace(event.Data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
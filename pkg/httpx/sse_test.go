@@ -0,0 +1,116 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestHandleSSE(t *testing.T) {
+	handler := httpx.HandleSSE(func(ctx context.Context, _ emptyRequest, send func(httpx.SSEEvent) error) error {
+		if err := send(httpx.SSEEvent{Event: "tick", ID: "1", Data: "a\nb"}); err != nil {
+			return err
+		}
+		return send(httpx.SSEEvent{Data: "c\r\nd\re"})
+	})
+
+	w := serve(handler, http.MethodGet, "/events")
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	want := "id: 1\nevent: tick\ndata: a\ndata: b\n\ndata: c\ndata: d\ndata: e\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestHandleSSERejectsLineBreaks(t *testing.T) {
+	for name, event := range map[string]httpx.SSEEvent{
+		"event": {Event: "tick\ndata: injected", Data: "x"},
+		"id":    {ID: "1\r\nevent: injected", Data: "x"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var sendErr error
+			handler := httpx.HandleSSE(func(ctx context.Context, _ emptyRequest, send func(httpx.SSEEvent) error) error {
+				sendErr = send(event)
+				return nil
+			})
+
+			w := serve(handler, http.MethodGet, "/events")
+			if !errors.Is(sendErr, httpx.ErrSSELineBreak) {
+				t.Errorf("send error = %v, want %v", sendErr, httpx.ErrSSELineBreak)
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("body = %q, want nothing written", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleSSEErrorBeforeStream(t *testing.T) {
+	handler := httpx.HandleSSE(func(ctx context.Context, _ emptyRequest, send func(httpx.SSEEvent) error) error {
+		return errNotFound
+	})
+
+	if w := serve(handler, http.MethodGet, "/events"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleSSEWithoutFlusher(t *testing.T) {
+	handler := httpx.HandleSSE(func(ctx context.Context, _ emptyRequest, send func(httpx.SSEEvent) error) error {
+		return send(httpx.SSEEvent{Data: "x"})
+	})
+
+	rec := httptest.NewRecorder()
+	handler(noFlusher{rec}, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestHandleSSEErrorAfterStart(t *testing.T) {
+	ticks := func(ctx context.Context, _ emptyRequest, send func(httpx.SSEEvent) error) error {
+		for _, data := range []string{"1", "2"} {
+			if err := send(httpx.SSEEvent{Event: "tick", Data: data}); err != nil {
+				return err
+			}
+		}
+		return errors.New("db is down")
+	}
+	writeComment := func(w http.ResponseWriter, _ *http.Request, err error) {
+		_, _ = io.WriteString(w, ": "+err.Error()+"\n\n")
+	}
+
+	tests := map[string]struct {
+		handler http.HandlerFunc
+		want    string
+	}{
+		"default": {
+			handler: httpx.HandleSSE(ticks),
+			want:    "event: tick\ndata: 1\n\nevent: tick\ndata: 2\n\nevent: error\ndata: Internal Server Error\n\n",
+		},
+		"custom handler": {
+			handler: httpx.HandleSSE(ticks, httpx.WithStreamErrorHandler(writeComment)),
+			want:    "event: tick\ndata: 1\n\nevent: tick\ndata: 2\n\n: db is down\n\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := serve(tt.handler, http.MethodGet, "/events")
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
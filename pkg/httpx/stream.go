@@ -0,0 +1,175 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+)
+
+// streamFlushEvery is a default number of encoded items after which streamed response is flushed
+const streamFlushEvery = 100
+
+// A StreamError is written as the last item or event of the stream when use case fails after the stream started.
+// HTTP status is already sent at that moment and can not be changed, so clients should check the last item.
+// Message of non-internal [errorsx.Errorx] is kept, other errors are reported as internal server error
+type StreamError struct {
+	Message string `json:"error" xml:"error" msgpack:"error" yaml:"error" csv:"error"`
+}
+
+// NewStreamError returns StreamError describing err, e.g. for close reason of WebSocket connection
+func NewStreamError(err error) StreamError {
+	if errx, ok := errorsx.As(err); ok && !errx.Internal() {
+		return StreamError{Message: errx.Error()}
+	}
+	return StreamError{Message: http.StatusText(http.StatusInternalServerError)}
+}
+
+// StreamFunc is a type to implement business logic functions with large results. Every item passed to send
+// is encoded to the client one at a time without keeping the whole result in memory
+type StreamFunc[Req any, Item any] func(ctx context.Context, req Req, send func(item Item) error) error
+
+// HandleStream is a variant of Handle for large responses. Items are encoded one by one using the configured encoder
+// and flushed every 100 items by default, see WithFlushEvery and WithFlushInterval.
+// With JSON encoder, including +json media types, they are written as a single array.
+//
+// Stream starts with the first sent item, errors returned by the use case before it are written as in Handle.
+// After that the status is already committed, so errors are logged and written to the stream as StreamError,
+// see WithStreamErrorHandler to change it
+//
+// Usage:
+//
+//	mux.HandleFunc("GET /export", httpx.HandleStream[Request, Row](func(ctx context.Context, req Request, send func(Row) error) error {
+//		for rows.Next() {
+//			if err := send(rows.Row()); err != nil {
+//				return err
+//			}
+//		}
+//		return rows.Err()
+//	}))
+func HandleStream[Req any, Item any, _Req Request[Req]](useCase StreamFunc[Req, Item], options ...Option) http.HandlerFunc {
+	var (
+		h         = applyOptions(options...)
+		noFlusher sync.Once
+	)
+
+	return h.wrap(func(w http.ResponseWriter, r *http.Request) {
+		var logger = h.loggerFor(r)
+
+		req, ok := bind[Req, _Req](&h, w, r, logger)
+		if !ok {
+			return
+		}
+
+		flushes := canFlush(w)
+		if !flushes {
+			noFlusher.Do(func() {
+				logger.DebugContext(r.Context(), "response writer does not support flushing, stream is not flushed")
+			})
+		}
+
+		w = NewContextWriter(r.Context(), w)
+
+		var (
+			ctx       = r.Context()
+			streamEnc = h.encoderFor(r)
+			enc       encoder.Encoder
+			jsonArray bool
+			count     int
+			lastFlush = h.now()
+		)
+
+		if contentTyper, ok := streamEnc.(encoder.ContentTyper); ok {
+			mt := mediaType(contentTyper.ContentType())
+			jsonArray = mt == "application/json" || strings.HasSuffix(mt, "+json")
+		}
+
+		flush := func() {
+			if flushes {
+				w.(http.Flusher).Flush()
+				lastFlush = h.now()
+			}
+		}
+
+		start := func() error {
+			if enc != nil {
+				return nil
+			}
+			if contentTyper, ok := streamEnc.(encoder.ContentTyper); ok && contentTyper.ContentType() != "" {
+				w.Header().Set("Content-Type", contentTyper.ContentType())
+			}
+			w.WriteHeader(h.successCode)
+			enc = streamEnc.New(w)
+			if jsonArray {
+				_, err := io.WriteString(w, "[")
+				return err
+			}
+			return nil
+		}
+
+		send := func(item Item) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := start(); err != nil {
+				return err
+			}
+			if jsonArray && count > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+
+			count++
+			if (h.flushEvery > 0 && count%h.flushEvery == 0) ||
+				(h.flushInterval > 0 && h.now().Sub(lastFlush) >= h.flushInterval) {
+				flush()
+			}
+
+			return nil
+		}
+
+		err := useCase(ctx, req, send)
+		if err != nil && enc == nil {
+			h.writeUseCaseError(w, r, err)
+			return
+		}
+		if err != nil && connectionClosed(err) {
+			logger.DebugContext(r.Context(), "client closed connection", slog.Any("err", err))
+			return
+		}
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to stream response", slog.Any("err", err))
+			if h.streamErrorHandler != nil {
+				h.streamErrorHandler(w, r, err)
+			} else {
+				if jsonArray && count > 0 {
+					_, _ = io.WriteString(w, ",")
+				}
+				if err = enc.Encode(NewStreamError(err)); err != nil {
+					logger.ErrorContext(r.Context(), "failed to write stream error", slog.Any("err", err))
+				}
+			}
+		}
+
+		if err = start(); err != nil {
+			logger.ErrorContext(r.Context(), "failed to stream response", slog.Any("err", err))
+			return
+		}
+		if jsonArray {
+			if _, err = io.WriteString(w, "]"); err != nil {
+				logger.ErrorContext(r.Context(), "failed to stream response", slog.Any("err", err))
+				return
+			}
+		}
+		flush()
+	})
+}
@@ -0,0 +1,148 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/encoder"
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// flushCounter counts flushes of the response
+type flushCounter struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (f *flushCounter) Flush() {
+	f.flushes++
+}
+
+// noFlusher hides Flush method of the response writer
+type noFlusher struct {
+	http.ResponseWriter
+}
+
+// count sends n integers with pause before every item
+func count(n int, pause time.Duration) httpx.StreamFunc[emptyRequest, int] {
+	return func(ctx context.Context, _ emptyRequest, send func(int) error) error {
+		for i := 0; i < n; i++ {
+			time.Sleep(pause)
+			if err := send(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestHandleStreamFlushes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		handler http.HandlerFunc
+		want    int
+	}{
+		"every 100 of 250": {handler: httpx.HandleStream(count(250, 0), httpx.WithFlushEvery(100)), want: 3},
+		"default every":    {handler: httpx.HandleStream(count(250, 0)), want: 3},
+		"disabled":         {handler: httpx.HandleStream(count(250, 0), httpx.WithFlushEvery(0)), want: 1},
+		"interval":         {handler: httpx.HandleStream(count(3, 10*time.Millisecond), httpx.WithFlushEvery(0), httpx.WithFlushInterval(5*time.Millisecond)), want: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := &flushCounter{ResponseRecorder: httptest.NewRecorder()}
+			tc.handler(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if w.flushes != tc.want {
+				t.Errorf("flushes = %d, want %d", w.flushes, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleStreamWithoutFlusher(t *testing.T) {
+	var (
+		logs    bytes.Buffer
+		handler = httpx.HandleStream(count(3, 0), httpx.WithFlushEvery(1), bufferLogger(&logs))
+	)
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler(noFlusher{rec}, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+		if got := rec.Body.String(); got != "[0\n,1\n,2\n]" {
+			t.Errorf("body = %q", got)
+		}
+		if rec.Flushed {
+			t.Error("response flushed, want no flushes")
+		}
+	}
+	if got := strings.Count(logs.String(), "does not support flushing"); got != 1 {
+		t.Errorf("logged %d times, want once", got)
+	}
+}
+
+// failAfter sends n integers, then fails with err
+func failAfter(n int, err error) httpx.StreamFunc[emptyRequest, int] {
+	return func(ctx context.Context, req emptyRequest, send func(int) error) error {
+		if sendErr := count(n, 0)(ctx, req, send); sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+}
+
+func TestHandleStreamError(t *testing.T) {
+	errConflict := errorsx.New(false, http.StatusConflict, "version changed")
+	writeComment := func(w http.ResponseWriter, _ *http.Request, err error) {
+		_, _ = io.WriteString(w, "# "+err.Error()+"\n")
+	}
+
+	tests := map[string]struct {
+		handler http.HandlerFunc
+		want    string
+	}{
+		"ndjson": {
+			handler: httpx.HandleStream(failAfter(3, errors.New("db is down")), httpx.WithEncoder(encoder.NdjsonEncoder)),
+			want:    "0\n1\n2\n" + `{"error":"Internal Server Error"}` + "\n",
+		},
+		"ndjson client error": {
+			handler: httpx.HandleStream(failAfter(3, errConflict), httpx.WithEncoder(encoder.NdjsonEncoder)),
+			want:    "0\n1\n2\n" + `{"error":"version changed"}` + "\n",
+		},
+		"json array": {
+			handler: httpx.HandleStream(failAfter(2, errors.New("db is down"))),
+			want:    "[0\n,1\n," + `{"error":"Internal Server Error"}` + "\n]",
+		},
+		"json suffix": {
+			handler: httpx.HandleStream(failAfter(2, errors.New("db is down")),
+				httpx.WithEncoder(encoder.WithContentType(encoder.JsonEncoder, "application/vnd.rester+json"))),
+			want: "[0\n,1\n," + `{"error":"Internal Server Error"}` + "\n]",
+		},
+		"custom handler": {
+			handler: httpx.HandleStream(failAfter(3, errors.New("db is down")),
+				httpx.WithEncoder(encoder.NdjsonEncoder), httpx.WithStreamErrorHandler(writeComment)),
+			want: "0\n1\n2\n# db is down\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := serve(tt.handler, http.MethodGet, "/")
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
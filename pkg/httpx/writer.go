@@ -0,0 +1,67 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+)
+
+// contextWriter fails writes once the context is done, unless final write is allowed with allowFinalWrite
+type contextWriter struct {
+	http.ResponseWriter
+	ctx   context.Context
+	final bool
+}
+
+// NewContextWriter wraps w so that Write returns error of ctx once it is done, encoders stop promptly
+// instead of blocking on a client which is gone. HandleStream, HandleSSE and handlers with WithTimeout use it
+// with the request context
+func NewContextWriter(ctx context.Context, w http.ResponseWriter) http.ResponseWriter {
+	return &contextWriter{ResponseWriter: w, ctx: ctx}
+}
+
+func (c *contextWriter) Write(b []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil && !c.final {
+		return 0, err
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *contextWriter) Flush() {
+	if c.ctx.Err() != nil {
+		return
+	}
+	_ = http.NewResponseController(c.ResponseWriter).Flush()
+}
+
+func (c *contextWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// allowFinalWrite lets writes pass through contextWriter wrapped by w after its context is done,
+// so error response written once the deadline is exceeded keeps its body
+func allowFinalWrite(w http.ResponseWriter) {
+	for {
+		if c, ok := w.(*contextWriter); ok {
+			c.final = true
+			return
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = unwrapper.Unwrap()
+	}
+}
+
+// canFlush reports whether the innermost writer of the chain wrapped by w supports flushing,
+// wrappers like ResponseRecorder have Flush method even when the writer they wrap has none
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			_, ok = w.(http.Flusher)
+			return ok
+		}
+		w = unwrapper.Unwrap()
+	}
+}
@@ -0,0 +1,89 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+func TestContextWriter(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		rec         = httptest.NewRecorder()
+		w           = httpx.NewContextWriter(ctx, rec)
+	)
+
+	if _, err := w.Write([]byte("a")); err != nil {
+		t.Fatalf("Write() before cancel error = %v", err)
+	}
+	cancel()
+	if _, err := w.Write([]byte("b")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Write() after cancel error = %v, want %v", err, context.Canceled)
+	}
+	w.(http.Flusher).Flush()
+
+	if got := rec.Body.String(); got != "a" {
+		t.Errorf("body = %q, want %q", got, "a")
+	}
+	if rec.Flushed {
+		t.Error("Flush() after cancel flushed underlying writer")
+	}
+}
+
+// unwrapOnly wraps writer without implementing http.Flusher
+type unwrapOnly struct {
+	http.ResponseWriter
+}
+
+func (u unwrapOnly) Unwrap() http.ResponseWriter {
+	return u.ResponseWriter
+}
+
+func TestContextWriterFlushUnwraps(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := httpx.NewContextWriter(context.Background(), unwrapOnly{ResponseWriter: rec})
+
+	w.(http.Flusher).Flush()
+	if !rec.Flushed {
+		t.Error("Flush() did not reach writer wrapped without Flush method")
+	}
+}
+
+func TestHandleStreamStopsOnCancel(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		sent        int
+		sendErr     error
+		handler     = httpx.HandleStream(func(ctx context.Context, _ emptyRequest, send func(int) error) error {
+			for i := 0; i < 1000; i++ {
+				if sendErr = send(i); sendErr != nil {
+					return sendErr
+				}
+				sent++
+				if sent == 3 {
+					cancel()
+				}
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		})
+	)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(ctx))
+
+	if !errors.Is(sendErr, context.Canceled) {
+		t.Errorf("send error = %v, want %v", sendErr, context.Canceled)
+	}
+	if sent != 3 {
+		t.Errorf("sent %d items, want 3", sent)
+	}
+	if got := w.Body.String(); got != "[0\n,1\n,2\n" {
+		t.Errorf("body = %q, want only items sent before cancel", got)
+	}
+}
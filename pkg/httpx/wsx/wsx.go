@@ -0,0 +1,77 @@
+// Package wsx provides WebSocket endpoints for httpx handlers
+package wsx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/coder/websocket"
+
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+// maxCloseReason is a maximum length of WebSocket close frame reason in bytes
+const maxCloseReason = 123
+
+// Func is a type to implement WebSocket business logic functions. Connection is closed with normal status
+// when the function returns nil
+type Func[Req any] func(ctx context.Context, req Req, conn *websocket.Conn) error
+
+// Handle is a variant of [httpx.Handle] for WebSocket endpoints. Request is bound and validated before the upgrade,
+// so errors are returned with a normal status. Context passed to the use case carries request id and logger
+// of the request. Client going away is reported as an error by conn.Read, endpoints that never read should call
+// conn.CloseRead to get a context cancelled on disconnect. Errors returned by the use case close the connection
+// with [websocket.StatusInternalError] and [httpx.StreamError] message as reason. Enabling [httpx.WithTimeout] limits
+// lifetime of the connection. Accept sets options of the upgrade, e.g. allowed origins and subprotocols,
+// nil accept upgrades only same origin requests
+//
+// Usage:
+//
+//	mux.HandleFunc("GET /echo", wsx.Handle[Request](nil, func(ctx context.Context, req Request, conn *websocket.Conn) error {
+//		for {
+//			typ, data, err := conn.Read(ctx)
+//			if err != nil {
+//				return err
+//			}
+//			if err = conn.Write(ctx, typ, data); err != nil {
+//				return err
+//			}
+//		}
+//	}))
+func Handle[Req any, _Req httpx.Request[Req]](accept *websocket.AcceptOptions, useCase Func[Req], options ...httpx.Option) http.HandlerFunc {
+	return httpx.HandleUpgrade[Req, _Req](func(w http.ResponseWriter, r *http.Request, req Req) {
+		var logger = httpx.LoggerFromContext(r.Context())
+
+		conn, err := websocket.Accept(w, r, accept)
+		if err != nil {
+			logger.Error("failed to upgrade connection", slog.Any("err", err))
+			return
+		}
+		defer conn.CloseNow()
+
+		err = useCase(r.Context(), req, conn)
+		switch {
+		case err == nil:
+			_ = conn.Close(websocket.StatusNormalClosure, "")
+		case websocket.CloseStatus(err) != -1 || connectionClosed(err):
+			logger.Debug("client closed connection", slog.Any("err", err))
+		default:
+			logger.Error("failed to handle connection", slog.Any("err", err))
+			reason := httpx.NewStreamError(err).Message
+			if len(reason) > maxCloseReason {
+				reason = reason[:maxCloseReason]
+			}
+			_ = conn.Close(websocket.StatusInternalError, reason)
+		}
+	}, options...)
+}
+
+// connectionClosed reports whether err is caused by client going away
+func connectionClosed(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
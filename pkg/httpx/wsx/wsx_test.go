@@ -0,0 +1,135 @@
+package wsx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+	"github.com/abdivasiyev/rester/pkg/httpx/wsx"
+)
+
+type roomRequest struct {
+	Room string
+}
+
+func (r *roomRequest) Bind(req *http.Request) error {
+	r.Room = req.URL.Query().Get("room")
+	return nil
+}
+
+func (r *roomRequest) Validate() error {
+	if r.Room == "" {
+		return errorsx.New(false, http.StatusBadRequest, "room is required")
+	}
+	return nil
+}
+
+func (r roomRequest) String() string {
+	return r.Room
+}
+
+func newServer(t *testing.T, useCase wsx.Func[roomRequest]) string {
+	t.Helper()
+
+	server := httptest.NewServer(wsx.Handle[roomRequest](nil, useCase,
+		httpx.WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))),
+	))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestHandleEcho(t *testing.T) {
+	url := newServer(t, func(ctx context.Context, req roomRequest, conn *websocket.Conn) error {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		return conn.Write(ctx, typ, append([]byte(req.Room+": "), data...))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url+"?room=lobby", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.CloseNow()
+
+	if err = conn.Write(ctx, websocket.MessageText, []byte("hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(data) != "lobby: hello" {
+		t.Errorf("message = %q, want %q", data, "lobby: hello")
+	}
+
+	if _, _, err = conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusNormalClosure {
+		t.Errorf("close status = %v, want %v", websocket.CloseStatus(err), websocket.StatusNormalClosure)
+	}
+}
+
+func TestHandleValidationBeforeUpgrade(t *testing.T) {
+	url := newServer(t, func(context.Context, roomRequest, *websocket.Conn) error {
+		t.Error("use case called for invalid request")
+		return nil
+	})
+
+	_, resp, err := websocket.Dial(context.Background(), url, nil)
+	if err == nil {
+		t.Fatal("Dial() succeeded, want error")
+	}
+	if resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("response = %v, want status %d", resp, http.StatusBadRequest)
+	}
+}
+
+func TestHandleUseCaseError(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		reason string
+	}{
+		"client error":   {err: errorsx.New(false, http.StatusConflict, "room is full"), reason: "room is full"},
+		"internal error": {err: errors.New("db is down"), reason: http.StatusText(http.StatusInternalServerError)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			url := newServer(t, func(context.Context, roomRequest, *websocket.Conn) error {
+				return tt.err
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, _, err := websocket.Dial(ctx, url+"?room=lobby", nil)
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			defer conn.CloseNow()
+
+			_, _, err = conn.Read(ctx)
+			var closeErr websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				t.Fatalf("Read() error = %v, want close error", err)
+			}
+			if closeErr.Code != websocket.StatusInternalError || closeErr.Reason != tt.reason {
+				t.Errorf("close = %v %q, want %v %q", closeErr.Code, closeErr.Reason, websocket.StatusInternalError, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// AsyncHandler passes records to the wrapped handler from a background goroutine, so logging does not wait
+// for slow writers. Records are queued in a buffer, Handle blocks when it is full, so no record is dropped.
+// Call Flush or Close before exit to write queued records
+type AsyncHandler struct {
+	handler slog.Handler
+	queue   *asyncQueue
+}
+
+type asyncRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+	flushed chan struct{}
+}
+
+// asyncQueue is shared by handlers derived with WithAttrs and WithGroup
+type asyncQueue struct {
+	mu      sync.RWMutex
+	closed  bool
+	records chan asyncRecord
+	done    chan struct{}
+}
+
+func NewAsyncHandler(handler slog.Handler, bufferSize int) *AsyncHandler {
+	q := &asyncQueue{
+		records: make(chan asyncRecord, max(bufferSize, 0)),
+		done:    make(chan struct{}),
+	}
+	go q.run()
+
+	return &AsyncHandler{handler: handler, queue: q}
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for item := range q.records {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		_ = item.handler.Handle(item.ctx, item.record)
+	}
+}
+
+func (a *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return a.handler.Enabled(ctx, level)
+}
+
+// Handle queues a copy of the record, records handled after Close are written synchronously
+func (a *AsyncHandler) Handle(ctx context.Context, record slog.Record) error {
+	a.queue.mu.RLock()
+	defer a.queue.mu.RUnlock()
+
+	if a.queue.closed {
+		return a.handler.Handle(ctx, record)
+	}
+
+	a.queue.records <- asyncRecord{ctx: context.WithoutCancel(ctx), handler: a.handler, record: record.Clone()}
+	return nil
+}
+
+func (a *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{handler: a.handler.WithAttrs(attrs), queue: a.queue}
+}
+
+func (a *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{handler: a.handler.WithGroup(name), queue: a.queue}
+}
+
+// Flush waits until records queued before the call are written or ctx is done, returns ctx error in the latter case
+func (a *AsyncHandler) Flush(ctx context.Context) error {
+	a.queue.mu.RLock()
+	if a.queue.closed {
+		a.queue.mu.RUnlock()
+		return nil
+	}
+
+	flushed := make(chan struct{})
+	select {
+	case a.queue.records <- asyncRecord{flushed: flushed}:
+		a.queue.mu.RUnlock()
+	case <-ctx.Done():
+		a.queue.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes queued records and stops the background goroutine. It is shared by derived handlers
+func (a *AsyncHandler) Close() error {
+	a.queue.mu.Lock()
+	if !a.queue.closed {
+		a.queue.closed = true
+		close(a.queue.records)
+	}
+	a.queue.mu.Unlock()
+
+	<-a.queue.done
+	return nil
+}
@@ -0,0 +1,99 @@
+package slogx_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abdivasiyev/rester/pkg/slogx"
+)
+
+// syncBuffer is a buffer safe to write from the background goroutine of AsyncHandler
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// blockingHandler reports every record to started and blocks it until release is closed
+type blockingHandler struct {
+	slog.Handler
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h blockingHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.started <- struct{}{}
+	<-h.release
+	return h.Handler.Handle(ctx, record)
+}
+
+func TestAsyncFlush(t *testing.T) {
+	var buf syncBuffer
+	logger := slogx.New(slogx.WithWriter(&buf), slogx.WithAsync(16))
+	defer func() { _ = slogx.Close(logger) }()
+
+	logger.With(slog.String("component", "test")).Info("first")
+	logger.Info("second")
+
+	if err := slogx.Flush(context.Background(), logger); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"first"`) || !strings.Contains(out, `"msg":"second"`) {
+		t.Errorf("output = %q, want both records", out)
+	}
+}
+
+func TestAsyncFlushDeadline(t *testing.T) {
+	var buf syncBuffer
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	handler := slogx.NewAsyncHandler(blockingHandler{
+		Handler: slog.NewJSONHandler(&buf, nil),
+		started: started,
+		release: release,
+	}, 0)
+	logger := slog.New(handler)
+
+	logger.Info("stuck")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := slogx.Flush(ctx, logger); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	if err := handler.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, `"msg":"stuck"`) {
+		t.Errorf("output = %q, want queued record after Close", out)
+	}
+}
+
+func TestFlushSyncLogger(t *testing.T) {
+	logger := slogx.New(slogx.WithWriter(&syncBuffer{}))
+	if err := slogx.Flush(context.Background(), logger); err != nil {
+		t.Errorf("Flush() error = %v, want nil", err)
+	}
+}
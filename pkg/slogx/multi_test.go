@@ -0,0 +1,46 @@
+package slogx_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/abdivasiyev/rester/pkg/slogx"
+)
+
+func TestWithHandlers(t *testing.T) {
+	var jsonBuf, textBuf bytes.Buffer
+	logger := slogx.New(slogx.WithHandlers(
+		slog.NewJSONHandler(&jsonBuf, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		slog.NewTextHandler(&textBuf, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)).With(slog.String("app", "api")).WithGroup("req")
+
+	logger.Info("hello", slog.Int("id", 1))
+	logger.Debug("details")
+
+	if want := `"msg":"hello","app":"api","req":{"id":1}}`; !strings.Contains(jsonBuf.String(), want) {
+		t.Errorf("JSON logs = %s, want %s", jsonBuf.String(), want)
+	}
+	if want := `msg=hello app=api req.id=1`; !strings.Contains(textBuf.String(), want) {
+		t.Errorf("text logs = %s, want %s", textBuf.String(), want)
+	}
+	if strings.Contains(jsonBuf.String(), "details") {
+		t.Errorf("JSON logs = %s, want no debug records", jsonBuf.String())
+	}
+	if !strings.Contains(textBuf.String(), "msg=details") {
+		t.Errorf("text logs = %s, want debug record", textBuf.String())
+	}
+}
+
+func TestWithWriters(t *testing.T) {
+	var stdout, file bytes.Buffer
+	slogx.New(slogx.WithWriters(&stdout, &file)).Info("hello")
+
+	if !strings.Contains(stdout.String(), `"msg":"hello"`) {
+		t.Errorf("first writer got %q", stdout.String())
+	}
+	if stdout.String() != file.String() {
+		t.Errorf("writers got %q and %q, want the same record", stdout.String(), file.String())
+	}
+}
@@ -1,16 +1,19 @@
 package slogx
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"os"
 )
 
 type logger struct {
-	handler slog.Handler
-	w       io.Writer
-	level   slog.Level
-	source  bool
+	handler  slog.Handler
+	w        io.Writer
+	level    slog.Level
+	source   bool
+	async    int
+	handlers []slog.Handler
 }
 
 func New(options ...Option) *slog.Logger {
@@ -23,6 +26,12 @@ func New(options ...Option) *slog.Logger {
 		l.w = os.Stdout
 	}
 
+	if len(l.handlers) == 1 {
+		l.handler = l.handlers[0]
+	} else if len(l.handlers) > 1 {
+		l.handler = &multiHandler{handlers: l.handlers}
+	}
+
 	if l.handler == nil {
 		l.handler = slog.NewJSONHandler(l.w, &slog.HandlerOptions{
 			Level:     l.level,
@@ -30,9 +39,31 @@ func New(options ...Option) *slog.Logger {
 		})
 	}
 
+	if l.async > 0 {
+		l.handler = NewAsyncHandler(l.handler, l.async)
+	}
+
 	return slog.New(l.handler)
 }
 
+// Flush waits until records of the logger created with WithAsync are written or ctx is done,
+// other loggers are not affected
+func Flush(ctx context.Context, logger *slog.Logger) error {
+	if flusher, ok := logger.Handler().(interface{ Flush(context.Context) error }); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// Close writes queued records and stops background goroutine of the logger created with WithAsync,
+// records logged afterwards are written synchronously. Other loggers are not affected
+func Close(logger *slog.Logger) error {
+	if closer, ok := logger.Handler().(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 type Option func(s *logger)
 
 func WithSource(source bool) Option {
@@ -52,3 +83,26 @@ func WithWriter(w io.Writer) Option {
 		s.w = w
 	}
 }
+
+// WithAsync writes records from a background goroutine with a queue of bufferSize records.
+// Use Flush or Close to write queued records before exit
+func WithAsync(bufferSize int) Option {
+	return func(s *logger) {
+		s.async = bufferSize
+	}
+}
+
+// WithWriters writes records to all writers, e.g. to stdout and a file
+func WithWriters(writers ...io.Writer) Option {
+	return func(s *logger) {
+		s.w = io.MultiWriter(writers...)
+	}
+}
+
+// WithHandlers dispatches every record to all handlers, e.g. JSON to a file and text to console.
+// Writer, level and source options are not applied to them
+func WithHandlers(handlers ...slog.Handler) Option {
+	return func(s *logger) {
+		s.handlers = append(s.handlers, handlers...)
+	}
+}
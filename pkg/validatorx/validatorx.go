@@ -0,0 +1,82 @@
+// Package validatorx adapts github.com/go-playground/validator to [httpx.StructValidator]
+package validatorx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+)
+
+type structValidator struct {
+	validate *validator.Validate
+}
+
+// New returns [httpx.StructValidator] backed by validate. If validate is nil, a new instance is created
+// which reports field names from `json` tags
+//
+// Usage:
+//
+//	mux.HandleFunc("POST /users", httpx.Handle[Request, Response](createUser, httpx.WithValidator(validatorx.New(nil))))
+func New(validate *validator.Validate) httpx.StructValidator {
+	if validate == nil {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		validate.RegisterTagNameFunc(jsonFieldName)
+	}
+
+	return &structValidator{
+		validate: validate,
+	}
+}
+
+func (s *structValidator) Validate(v any) error {
+	err := s.validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var invalidErr *validator.InvalidValidationError
+	if errors.As(err, &invalidErr) {
+		return errorsx.New(true, http.StatusInternalServerError, invalidErr.Error())
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	fields := make([]errorsx.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, errorsx.FieldError{
+			Field:   fieldErr.Field(),
+			Message: message(fieldErr),
+		})
+	}
+
+	return errorsx.NewValidationError(fields...)
+}
+
+func message(fieldErr validator.FieldError) string {
+	if fieldErr.Param() == "" {
+		return fmt.Sprintf("failed on %q validation", fieldErr.Tag())
+	}
+	return fmt.Sprintf("failed on %q validation", fieldErr.Tag()+"="+fieldErr.Param())
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
@@ -0,0 +1,125 @@
+package validatorx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/abdivasiyev/rester/pkg/errorsx"
+	"github.com/abdivasiyev/rester/pkg/httpx"
+	"github.com/abdivasiyev/rester/pkg/validatorx"
+)
+
+type user struct {
+	Name  string `json:"name" validate:"required"`
+	Age   int    `json:"age" validate:"gte=18"`
+	Email string `validate:"omitempty,email"`
+	Note  string `json:"-" validate:"max=3"`
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		src  any
+		want []errorsx.FieldError
+	}{
+		"valid": {src: user{Name: "bob", Age: 18}},
+		"invalid fields": {
+			src: user{Age: 17, Email: "bob", Note: "long"},
+			want: []errorsx.FieldError{
+				{Field: "name", Message: `failed on "required" validation`},
+				{Field: "age", Message: `failed on "gte=18" validation`},
+				{Field: "Email", Message: `failed on "email" validation`},
+				{Field: "Note", Message: `failed on "max=3" validation`},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validatorx.New(nil).Validate(tt.src)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			vErr, ok := errorsx.AsValidation(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want ValidationError", err)
+			}
+			if len(vErr.Fields) != len(tt.want) {
+				t.Fatalf("fields = %v, want %v", vErr.Fields, tt.want)
+			}
+			for i, field := range vErr.Fields {
+				if field != tt.want[i] {
+					t.Errorf("field %d = %v, want %v", i, field, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateInvalidValue(t *testing.T) {
+	err := validatorx.New(validator.New()).Validate(42)
+
+	errx, ok := errorsx.As(err)
+	if !ok || !errx.Internal() || errx.Code() != http.StatusInternalServerError {
+		t.Errorf("Validate() error = %v, want internal error", err)
+	}
+	var vErr *errorsx.ValidationError
+	if errors.As(err, &vErr) {
+		t.Errorf("Validate() error = %v, want no ValidationError", err)
+	}
+}
+
+type createRequest struct {
+	httpx.DefaultRequest
+	user
+}
+
+func (r *createRequest) Bind(req *http.Request) error {
+	return httpx.BindJSON(req, &r.user)
+}
+
+func (r createRequest) String() string {
+	return r.Name
+}
+
+func TestWithValidator(t *testing.T) {
+	handler := httpx.Handle(func(_ context.Context, req createRequest) (string, error) {
+		return "hello " + req.Name, nil
+	}, httpx.WithValidator(validatorx.New(nil)), httpx.WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))))
+
+	tests := map[string]struct {
+		body string
+		code int
+		want string
+	}{
+		"valid":   {body: `{"name":"bob","age":20}`, code: http.StatusOK, want: `"hello bob"` + "\n"},
+		"invalid": {body: `{"age":20}`, code: http.StatusUnprocessableEntity, want: `{"message":"validation failed","fields":[{"field":"name","message":"failed on \"required\" validation"}]}` + "\n"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, want %d", w.Code, tt.code)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}